@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+
+	auth "github.com/abbot/go-http-auth"
+)
+
+var logins *throttle
+
+// authWrap protects a handler with the configured authentication, including
+// the second factor of users who enrolled for two-factor authentication.
+func authWrap(fn auth.AuthenticatedHandlerFunc) http.HandlerFunc {
+	return loginWrap(twoFactorWrap(fn))
+}
+
+// loginWrap authenticates the user of a request. Requests coming from a
+// trusted reverse proxy are authenticated by its headers; all other requests
+// fall back to HTTP Basic authentication.
+func loginWrap(fn auth.AuthenticatedHandlerFunc) http.HandlerFunc {
+	basic := basicAuth(fn)
+	return func(w http.ResponseWriter, r *http.Request) {
+		username := proxyUser(r)
+		if username == "" {
+			basic(w, r)
+			return
+		}
+		fn(w, &auth.AuthenticatedRequest{Request: *r, Username: username})
+	}
+}
+
+// basicAuth is like authenticator.Wrap, but counts failed logins per remote
+// address and per username and refuses attempts while either is throttled.
+func basicAuth(fn auth.AuthenticatedHandlerFunc) http.HandlerFunc {
+	if logins == nil {
+		return authenticator.Wrap(fn)
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, _, given := r.BasicAuth()
+		keys := []string{"address:" + remoteHost(r.RemoteAddr)}
+		if given {
+			keys = append(keys, "user:"+username)
+		}
+		if wait := logins.wait(keys...); wait > 0 {
+			log.Printf("Blocked login attempt for %q from %s, throttled for another %v\n", username, r.RemoteAddr, wait)
+			w.Header().Set("Retry-After", fmt.Sprintf("%.0f", wait.Seconds()+0.5))
+			http.Error(w, "Too many failed login attempts", http.StatusTooManyRequests)
+			return
+		}
+		if authenticated := authenticator.CheckAuth(r); authenticated != "" {
+			logins.succeed("user:" + authenticated)
+			fn(w, &auth.AuthenticatedRequest{Request: *r, Username: authenticated})
+			return
+		}
+		if given {
+			log.Printf("Failed login attempt for %q from %s\n", username, r.RemoteAddr)
+			logins.fail(keys...)
+		}
+		authenticator.RequireAuth(w, r)
+	}
+}
+
+func remoteHost(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	return host
+}
+
+// proxyUser returns the username passed along by a trusted reverse proxy, or
+// an empty string if proxy authentication is disabled or the request did not
+// come through a trusted proxy.
+func proxyUser(r *http.Request) string {
+	if !conf.ProxyAuth.Enabled || !trustedProxy(r.RemoteAddr) {
+		return ""
+	}
+	return r.Header.Get(conf.ProxyAuth.UserHeader)
+}
+
+func trustedProxy(address string) bool {
+	ip := net.ParseIP(remoteHost(address))
+	if ip == nil {
+		return false
+	}
+	for _, network := range conf.ProxyAuth.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestAuthor returns the commit author for an authenticated request. The
+// name and email sent by a trusted proxy take precedence over the values in
+// the user configuration.
+func requestAuthor(r *auth.AuthenticatedRequest) author {
+	user := conf.Auth[r.Username]
+	a := author{Name: user.Name, Email: user.Email}
+	if proxyUser(&r.Request) == "" {
+		return a
+	}
+	if name := r.Header.Get(conf.ProxyAuth.NameHeader); name != "" {
+		a.Name = name
+	}
+	if email := r.Header.Get(conf.ProxyAuth.EmailHeader); email != "" {
+		a.Email = email
+	}
+	if a.Name == "" {
+		a.Name = r.Username
+	}
+	return a
+}
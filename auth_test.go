@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	auth "github.com/abbot/go-http-auth"
+)
+
+func loadTestProxyAuth(t *testing.T) {
+	c, err := loadConfigFromFile(testConfigFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.ProxyAuth.Enabled = true
+	c.ProxyAuth.TrustedProxies = []string{"10.0.0.0/8", "::1/128"}
+	c.loadAuth()
+	if err = c.loadProxies(); err != nil {
+		t.Fatal(err)
+	}
+	conf = c
+}
+
+func TestTrustedProxy(t *testing.T) {
+	loadTestProxyAuth(t)
+	defer func() { conf = config{} }()
+
+	addresses := map[string]bool{
+		"10.1.2.3:41234":    true,
+		"[::1]:41234":       true,
+		"192.168.1.1:41234": false,
+		"127.0.0.1:41234":   false,
+		"garbage":           false,
+	}
+	for address, trusted := range addresses {
+		if trustedProxy(address) != trusted {
+			t.Errorf("Trust of %s should be %v, but was %v", address, trusted, !trusted)
+		}
+	}
+}
+
+func TestRequestAuthorFromProxy(t *testing.T) {
+	loadTestProxyAuth(t)
+	defer func() { conf = config{} }()
+
+	r, _ := http.NewRequest("POST", "/save/home", nil)
+	r.RemoteAddr = "10.1.2.3:41234"
+	r.Header.Set("X-Remote-User", "alice")
+	r.Header.Set("X-Remote-Name", "Alice")
+	r.Header.Set("X-Remote-Email", "alice@example.com")
+
+	username := proxyUser(r)
+	if username != "alice" {
+		t.Errorf("Proxy user should equal >alice<, but is >%s<", username)
+	}
+	a := requestAuthor(&auth.AuthenticatedRequest{Request: *r, Username: username})
+	if a.String() != "Alice <alice@example.com>" {
+		t.Errorf("Author should equal >Alice <alice@example.com><, but is >%s<", a.String())
+	}
+
+	r.RemoteAddr = "192.168.1.1:41234"
+	if username = proxyUser(r); username != "" {
+		t.Errorf("Proxy headers from an untrusted address should be ignored, but got >%s<", username)
+	}
+	a = requestAuthor(&auth.AuthenticatedRequest{Request: *r, Username: "goiki"})
+	if a.String() != "Goiki <goiki@example.com>" {
+		t.Errorf("Author should equal >Goiki <goiki@example.com><, but is >%s<", a.String())
+	}
+}
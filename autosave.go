@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	auth "github.com/abbot/go-http-auth"
+)
+
+var autosaves *autosaveStore
+
+// autosave is an edit in progress, kept outside of the history until the
+// page is saved.
+type autosave struct {
+	Username string
+	Title    string
+	Body     string
+	Saved    time.Time
+}
+
+// autosaveStore keeps the autosaves of all users in a JSON file in the state
+// directory, at most one per user and page.
+type autosaveStore struct {
+	sync.Mutex
+	file  string
+	saves []*autosave
+	now   func() time.Time
+}
+
+func loadAutosaveStore(file string) (*autosaveStore, error) {
+	s := &autosaveStore{file: file, now: time.Now}
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return s, json.Unmarshal(data, &s.saves)
+}
+
+func (s *autosaveStore) save() error {
+	return saveJSON(s.file, s.saves)
+}
+
+func (s *autosaveStore) find(username string, title string) int {
+	for i, a := range s.saves {
+		if a.Username == username && a.Title == title {
+			return i
+		}
+	}
+	return -1
+}
+
+// put keeps body as the edit of title in progress by username.
+func (s *autosaveStore) put(username string, title string, body string) error {
+	s.Lock()
+	defer s.Unlock()
+	a := &autosave{Username: username, Title: title, Body: body, Saved: s.now()}
+	if i := s.find(username, title); i >= 0 {
+		s.saves[i] = a
+	} else {
+		s.saves = append(s.saves, a)
+	}
+	return s.save()
+}
+
+// get returns the edit of title in progress by username, if any.
+func (s *autosaveStore) get(username string, title string) (autosave, bool) {
+	s.Lock()
+	defer s.Unlock()
+	if i := s.find(username, title); i >= 0 {
+		return *s.saves[i], true
+	}
+	return autosave{}, false
+}
+
+// discard drops the edit of title in progress by username, once it is saved
+// or no longer wanted.
+func (s *autosaveStore) discard(username string, title string) error {
+	s.Lock()
+	defer s.Unlock()
+	i := s.find(username, title)
+	if i < 0 {
+		return nil
+	}
+	s.saves = append(s.saves[:i], s.saves[i+1:]...)
+	return s.save()
+}
+
+// autosaveHandler keeps the body posted by the editor every so often
+// (POST), or drops it when the user does not want it back (DELETE).
+func autosaveHandler(w http.ResponseWriter, r *auth.AuthenticatedRequest, title string) {
+	if autosaves == nil {
+		http.NotFound(w, &r.Request)
+		return
+	}
+	var err error
+	switch r.Method {
+	case "POST":
+		err = autosaves.put(r.Username, title, r.FormValue("body"))
+	case "DELETE":
+		err = autosaves.discard(r.Username, title)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAutosaveStore(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "goiki-autosave")
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "autosave.json")
+	s, err := loadAutosaveStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2015, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	if _, ok := s.get("goiki", "bicycle"); ok {
+		t.Errorf("There should be no autosave yet")
+	}
+	s.put("goiki", "bicycle", "Two wheels")
+	s.put("goiki", "bicycle", "Two wheels and a bell")
+	s.put("test", "bicycle", "Three wheels")
+	s.put("goiki", "tricycle", "Three wheels")
+
+	s, _ = loadAutosaveStore(file)
+	if a, ok := s.get("goiki", "bicycle"); !ok || a.Body != "Two wheels and a bell" || !a.Saved.Equal(now) {
+		t.Errorf("Autosave should equal >Two wheels and a bell<, but is %+v", a)
+	}
+	if a, _ := s.get("test", "bicycle"); a.Body != "Three wheels" {
+		t.Errorf("Autosaves should be kept per user, but is %+v", a)
+	}
+
+	if err = s.discard("goiki", "bicycle"); err != nil {
+		t.Errorf("Unable to discard autosave: %v", err)
+	}
+	s, _ = loadAutosaveStore(file)
+	if _, ok := s.get("goiki", "bicycle"); ok {
+		t.Errorf("Autosave should be discarded")
+	}
+	if _, ok := s.get("goiki", "tricycle"); !ok {
+		t.Errorf("Other autosaves should be kept")
+	}
+}
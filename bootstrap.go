@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const helpPage = "help"
+
+// initWiki sets up a new wiki in dir: a starter configuration, unless one
+// was given with -c, and the data repo with an index and a help page,
+// committed by the first configured user. Relative paths in the starter
+// configuration are taken relative to dir, where Goiki is meant to be run.
+func initWiki(dir string) error {
+	if len(dir) == 0 {
+		dir = "."
+	}
+
+	var err error
+	var configPath, dataDir string
+	if len(configFile) == 0 {
+		if conf, err = loadConfig(defaultConfig()); err != nil {
+			return err
+		}
+		configPath = filepath.Join(dir, "goiki.toml")
+		if _, err = os.Stat(configPath); err == nil {
+			return fmt.Errorf("%s already exists", configPath)
+		}
+		dataDir = conf.DataDir
+		if !filepath.IsAbs(dataDir) {
+			dataDir = filepath.Join(dir, dataDir)
+		}
+	} else {
+		if conf, err = loadConfigFromFile(configFile); err != nil {
+			return err
+		}
+		dataDir = conf.DataDir
+	}
+
+	var r *gogit.Repository
+	if conf.Storage == "files" {
+		err = os.MkdirAll(dataDir, 0777)
+	} else {
+		r, err = gogit.PlainInit(dataDir, false)
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to create the repo at %s: %v", dataDir, err)
+	}
+
+	seed := map[string]string{
+		fileName(conf.IndexPage): _bundle["seed/index.md"],
+		fileName(helpPage):       _bundle["seed/help.md"],
+	}
+	committer := author{Name: "Goiki"}
+	if len(conf.Users) > 0 {
+		committer = author{Name: conf.Users[0].Name, Email: conf.Users[0].Email}
+	}
+	message := "Initialize the wiki"
+
+	// Plain files are simply written; with Git, all pages go into the first
+	// commit.
+	if r == nil {
+		if store, err = openStorage(conf.Storage, dataDir); err != nil {
+			return err
+		}
+		for file, content := range seed {
+			if err = store.Write(file, []byte(content), message, committer); err != nil {
+				return err
+			}
+		}
+	} else {
+		w, err := r.Worktree()
+		if err != nil {
+			return err
+		}
+		for file, content := range seed {
+			if err = ioutil.WriteFile(dataPath(dataDir, file), []byte(content), 0600); err != nil {
+				return err
+			}
+			if _, err = w.Add(file); err != nil {
+				return err
+			}
+		}
+		signature := &object.Signature{Name: committer.Name, Email: committer.Email, When: time.Now()}
+		if _, err = w.Commit(message, &gogit.CommitOptions{Author: signature, Committer: signature}); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Created the wiki at %s\n", dataDir)
+
+	if len(configPath) > 0 {
+		if err = ioutil.WriteFile(configPath, []byte(defaultConfig()), 0600); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote the configuration to %s\n", configPath)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitWiki(t *testing.T) {
+	defer func() { conf = config{} }()
+	dir, _ := ioutil.TempDir("", "goiki-init")
+	defer os.RemoveAll(dir)
+	dir = filepath.Join(dir, "wiki")
+
+	if err := initWiki(dir); err != nil {
+		t.Fatalf("Unable to initialize the wiki: %v", err)
+	}
+	config, _ := ioutil.ReadFile(filepath.Join(dir, "goiki.toml"))
+	if string(config) != defaultConfig() {
+		t.Errorf("Configuration should equal the default configuration, but was >%s<", config)
+	}
+	for _, file := range []string{"home.md", "help.md"} {
+		if content, err := ioutil.ReadFile(filepath.Join(dir, "data", file)); len(content) == 0 || err != nil {
+			t.Errorf("Page %s should be seeded (error: %v)", file, err)
+		}
+	}
+
+	cmd := exec.Command("git", "log", "--format=%an <%ae> %s", "--name-only")
+	cmd.Dir = filepath.Join(dir, "data")
+	out, err := cmd.Output()
+	expected := "Goiki <goiki@example.com> Initialize the wiki\n\nhelp.md\nhome.md\n"
+	if string(out) != expected || err != nil {
+		t.Errorf("Log should equal >%s<, but was >%s< (error: %v)", expected, out, err)
+	}
+
+	if err = initWiki(dir); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Initializing the wiki twice should fail, but got %v", err)
+	}
+}
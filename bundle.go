@@ -5,19 +5,29 @@ package main
 var _bundle = map[string]string{
 	"templates/_footer.html": `e3tkZWZpbmUgImZvb3RlciJ9fQogIDwvZGl2PiA8IS0tIC8uY29udGFpbmVyIC0tPgoKICA8IS0tIGpRdWVyeSAobmVjZXNzYXJ5IGZvciBCb290c3RyYXAncyBKYXZhU2NyaXB0IHBsdWdpbnMpIC0tPgogIDxzY3JpcHQgc3JjPSIvc3RhdGljL2pzL2pxdWVyeS0xLjExLjEubWluLmpzIj48L3NjcmlwdD4KCiAgPCEtLSBCb290c3RyYXAgSmF2YVNjcmlwdCBwbHVnaW5zIC0tPgogIDxzY3JpcHQgc3JjPSIvc3RhdGljL2pzL2Jvb3RzdHJhcC5taW4uanMiPjwvc2NyaXB0Pgo8L2JvZHk+CjwvaHRtbD4Ke3tlbmR9fQo=
 `,
-	"templates/_header.html": `e3tkZWZpbmUgImhlYWRlciJ9fQo8IURPQ1RZUEUgaHRtbD4KPGh0bWwgbGFuZz0iZW4iPgogIDxoZWFkPgogICAgPG1ldGEgY2hhcnNldD0idXRmLTgiPgogICAgPG1ldGEgaHR0cC1lcXVpdj0iWC1VQS1Db21wYXRpYmxlIiBjb250ZW50PSJJRT1lZGdlIj4KICAgIDxtZXRhIG5hbWU9InZpZXdwb3J0IiBjb250ZW50PSJ3aWR0aD1kZXZpY2Utd2lkdGgsIGluaXRpYWwtc2NhbGU9MSI+CiAgICA8bWV0YSBuYW1lPSJkZXNjcmlwdGlvbiIgY29udGVudD0iIj4KICAgIDxtZXRhIG5hbWU9ImF1dGhvciIgY29udGVudD0iIj4KICAgIDxsaW5rIHJlbD0iaWNvbiIgaHJlZj0iL2Zhdmljb24uaWNvIj4KICAgIDx0aXRsZT57ey5UaXRsZX19PC90aXRsZT4KCiAgICA8IS0tIEJvb3RzdHJhcCAtLT4KICAgIDxsaW5rIGhyZWY9Ii9zdGF0aWMvY3NzL2Jvb3Rzd2F0Y2gte3suVGhlbWV9fS5taW4uY3NzIiByZWw9InN0eWxlc2hlZXQiPgogIDwvaGVhZD4KPGJvZHkgc3R5bGU9InBhZGRpbmctdG9wOiA2MHB4Ij4KCiAgPG5hdiBjbGFzcz0ibmF2YmFyIG5hdmJhci1kZWZhdWx0IG5hdmJhci1maXhlZC10b3AiIHJvbGU9Im5hdmlnYXRpb24iPgogICAgPGRpdiBjbGFzcz0iY29udGFpbmVyIj4KICAgICAgPGRpdiBjbGFzcz0ibmF2YmFyLWhlYWRlciI+CiAgICAgICAgPGJ1dHRvbiB0eXBlPSJidXR0b24iIGNsYXNzPSJuYXZiYXItdG9nZ2xlIGNvbGxhcHNlZCIgZGF0YS10b2dnbGU9ImNvbGxhcHNlIiBkYXRhLXRhcmdldD0iI25hdmJhciIgYXJpYS1leHBhbmRlZD0iZmFsc2UiIGFyaWEtY29udHJvbHM9Im5hdmJhciI+CiAgICAgICAgICA8c3BhbiBjbGFzcz0ic3Itb25seSI+VG9nZ2xlIG5hdmlnYXRpb248L3NwYW4+CiAgICAgICAgICA8c3BhbiBjbGFzcz0iaWNvbi1iYXIiPjwvc3Bhbj4KICAgICAgICAgIDxzcGFuIGNsYXNzPSJpY29uLWJhciI+PC9zcGFuPgogICAgICAgICAgPHNwYW4gY2xhc3M9Imljb24tYmFyIj48L3NwYW4+CiAgICAgICAgPC9idXR0b24+CiAgICAgICAgPGEgY2xhc3M9Im5hdmJhci1icmFuZCIgaHJlZj0iLyI+e3suU2l0ZU5hbWV9fTwvYT4KICAgICAgPC9kaXY+CiAgICAgIDxkaXYgaWQ9Im5hdmJhciIgY2xhc3M9ImNvbGxhcHNlIG5hdmJhci1jb2xsYXBzZSI+CiAgICAgICAgPHVsIGNsYXNzPSJuYXYgbmF2YmFyLW5hdiI+CiAgICAgICAgICA8bGk+PGEgaHJlZj0iL3ZpZXcve3suVGl0bGV9fSI+VmlldzwvYT48L2xpPgogICAgICAgICAgPGxpPjxhIGhyZWY9Ii9lZGl0L3t7LlRpdGxlfX0iPkVkaXQ8L2E+PC9saT4KICAgICAgICAgIDxsaT48YSBocmVmPSIvaGlzdG9yeS97ey5UaXRsZX19Ij5IaXN0b3J5PC9hPjwvbGk+CiAgICAgICAgPC91bD4KICAgICAgICA8Zm9ybSByb2xlPSJmb3JtIiBhY3Rpb249Ii9zZWFyY2gvIiBtZXRob2Q9IlBPU1QiIGNsYXNzPSJuYXZiYXItZm9ybSBuYXZiYXItcmlnaHQiPgogICAgICAgICAgPGlucHV0IHR5cGU9InRleHQiIG5hbWU9InNlYXJjaCIgY2xhc3M9ImZvcm0tY29udHJvbCIgcGxhY2Vob2xkZXI9IlNlYXJjaC4uLiI+CiAgICAgICAgPC9mb3JtPgogICAgICA8L2Rpdj48IS0tIC8ubmF2LWNvbGxhcHNlIC0tPgogICAgPC9kaXY+CiAgPC9uYXY+CgogIDxkaXYgY2xhc3M9ImNvbnRhaW5lciI+Cnt7ZW5kfX0K
+	"templates/_header.html": `e3tkZWZpbmUgImhlYWRlciJ9fQo8IURPQ1RZUEUgaHRtbD4KPGh0bWwgbGFuZz0iZW4iPgogIDxoZWFkPgogICAgPG1ldGEgY2hhcnNldD0idXRmLTgiPgogICAgPG1ldGEgaHR0cC1lcXVpdj0iWC1VQS1Db21wYXRpYmxlIiBjb250ZW50PSJJRT1lZGdlIj4KICAgIDxtZXRhIG5hbWU9InZpZXdwb3J0IiBjb250ZW50PSJ3aWR0aD1kZXZpY2Utd2lkdGgsIGluaXRpYWwtc2NhbGU9MSI+CiAgICA8bWV0YSBuYW1lPSJkZXNjcmlwdGlvbiIgY29udGVudD0iIj4KICAgIDxtZXRhIG5hbWU9ImF1dGhvciIgY29udGVudD0iIj4KICAgIDxsaW5rIHJlbD0iaWNvbiIgaHJlZj0iL2Zhdmljb24uaWNvIj4KICAgIDx0aXRsZT57e3dpdGggdGl0bGVPZiAufX17e2h0bWwgLn19e3tlbHNlfX17ey5UaXRsZX19e3tlbmR9fTwvdGl0bGU+CgogICAgPCEtLSBCb290c3RyYXAgLS0+CiAgICA8bGluayBocmVmPSIvc3RhdGljL2Nzcy9ib290c3dhdGNoLXt7LlRoZW1lfX0ubWluLmNzcyIgcmVsPSJzdHlsZXNoZWV0Ij4KICA8L2hlYWQ+Cjxib2R5IHN0eWxlPSJwYWRkaW5nLXRvcDogNjBweCI+CgogIDxuYXYgY2xhc3M9Im5hdmJhciBuYXZiYXItZGVmYXVsdCBuYXZiYXItZml4ZWQtdG9wIiByb2xlPSJuYXZpZ2F0aW9uIj4KICAgIDxkaXYgY2xhc3M9ImNvbnRhaW5lciI+CiAgICAgIDxkaXYgY2xhc3M9Im5hdmJhci1oZWFkZXIiPgogICAgICAgIDxidXR0b24gdHlwZT0iYnV0dG9uIiBjbGFzcz0ibmF2YmFyLXRvZ2dsZSBjb2xsYXBzZWQiIGRhdGEtdG9nZ2xlPSJjb2xsYXBzZSIgZGF0YS10YXJnZXQ9IiNuYXZiYXIiIGFyaWEtZXhwYW5kZWQ9ImZhbHNlIiBhcmlhLWNvbnRyb2xzPSJuYXZiYXIiPgogICAgICAgICAgPHNwYW4gY2xhc3M9InNyLW9ubHkiPlRvZ2dsZSBuYXZpZ2F0aW9uPC9zcGFuPgogICAgICAgICAgPHNwYW4gY2xhc3M9Imljb24tYmFyIj48L3NwYW4+CiAgICAgICAgICA8c3BhbiBjbGFzcz0iaWNvbi1iYXIiPjwvc3Bhbj4KICAgICAgICAgIDxzcGFuIGNsYXNzPSJpY29uLWJhciI+PC9zcGFuPgogICAgICAgIDwvYnV0dG9uPgogICAgICAgIDxhIGNsYXNzPSJuYXZiYXItYnJhbmQiIGhyZWY9Ii8iPnt7LlNpdGVOYW1lfX08L2E+CiAgICAgIDwvZGl2PgogICAgICA8ZGl2IGlkPSJuYXZiYXIiIGNsYXNzPSJjb2xsYXBzZSBuYXZiYXItY29sbGFwc2UiPgogICAgICAgIDx1bCBjbGFzcz0ibmF2IG5hdmJhci1uYXYiPgogICAgICAgICAgPGxpPjxhIGhyZWY9Ii92aWV3L3t7LlRpdGxlfX0iPlZpZXc8L2E+PC9saT4KICAgICAgICAgIHt7aWYgbm90IGV4cG9ydGVkfX0KICAgICAgICAgIDxsaT48YSBocmVmPSIvZWRpdC97ey5UaXRsZX19Ij5FZGl0PC9hPjwvbGk+CiAgICAgICAgICA8bGk+PGEgaHJlZj0iL2hpc3Rvcnkve3suVGl0bGV9fSI+SGlzdG9yeTwvYT48L2xpPgogICAgICAgICAgPGxpPjxhIGhyZWY9Ii9jaGFuZ2VzLyI+Q2hhbmdlczwvYT48L2xpPgogICAgICAgICAge3tlbmR9fQogICAgICAgIDwvdWw+CiAgICAgICAge3tpZiBub3QgZXhwb3J0ZWR9fQogICAgICAgIDxmb3JtIHJvbGU9ImZvcm0iIGFjdGlvbj0iL3NlYXJjaC8iIG1ldGhvZD0iUE9TVCIgY2xhc3M9Im5hdmJhci1mb3JtIG5hdmJhci1yaWdodCI+CiAgICAgICAgICA8aW5wdXQgdHlwZT0idGV4dCIgbmFtZT0ic2VhcmNoIiBjbGFzcz0iZm9ybS1jb250cm9sIiBwbGFjZWhvbGRlcj0iU2VhcmNoLi4uIj4KICAgICAgICA8L2Zvcm0+CiAgICAgICAge3tlbmR9fQogICAgICA8L2Rpdj48IS0tIC8ubmF2LWNvbGxhcHNlIC0tPgogICAgPC9kaXY+CiAgPC9uYXY+CgogIDxkaXYgY2xhc3M9ImNvbnRhaW5lciI+Cnt7ZW5kfX0K
 `,
-	"templates/edit.html": `e3tkZWZpbmUgImVkaXQifX0Ke3t0ZW1wbGF0ZSAiaGVhZGVyIiAufX0KCiAgICA8aDE+RWRpdGluZyB7ey5UaXRsZX19PC9oMT4KCiAgICA8Zm9ybSByb2xlPSJmb3JtIiBhY3Rpb249Ii9zYXZlL3t7LlRpdGxlfX0iIG1ldGhvZD0iUE9TVCI+CiAgICAgIDxkaXYgY2xhc3M9ImZvcm0tZ3JvdXAgY29sLW1kLTEyIj4KICAgICAgICA8dGV4dGFyZWEgbmFtZT0iYm9keSIgY2xhc3M9ImZvcm0tY29udHJvbCIgcm93cz0iOCI+e3suQm9keX19PC90ZXh0YXJlYT4KICAgICAgPC9kaXY+CiAgICAgIDxkaXYgY2xhc3M9ImZvcm0tZ3JvdXAgY29sLW1kLTEyIj4KICAgICAgICA8aW5wdXQgbmFtZT0iZGVzY3JpcHRpb24iIGNsYXNzPSJmb3JtLWNvbnRyb2wiIHR5cGU9InRleHQiIHBsYWNlaG9sZGVyPSJVcGRhdGUge3suVGl0bGV9fSI+CiAgICAgIDwvZGl2PgogICAgICA8ZGl2IGNsYXNzPSJmb3JtLWdyb3VwIGNvbC1tZC0xMiI+CiAgICAgICAgPGJ1dHRvbiB0eXBlPSJzdWJtaXQiIGNsYXNzPSJidG4gYnRuLWRlZmF1bHQiPlNhdmU8L2J1dHRvbj4KICAgICAgPC9kaXY+CiAgICA8L2Zvcm0+Cgp7e3RlbXBsYXRlICJmb290ZXIifX0Ke3tlbmR9fQo=
+	"templates/change.html": `e3tkZWZpbmUgImNoYW5nZSJ9fQp7e3RlbXBsYXRlICJoZWFkZXIiIC59fQoKICAgIDxoMT57e2h0bWwgLkNoYW5nZS5TdW1tYXJ5fX0gPHNtYWxsPiN7ey5DaGFuZ2UuSUR9fTwvc21hbGw+PC9oMT4KCiAgICA8cD4KICAgICAge3todG1sIC5DaGFuZ2UuQXV0aG9yLk5hbWV9fSBhc2tzIHRvIG1lcmdlIHRoZSBkcmFmdAogICAgICA8YSBocmVmPSIvdmlldy8/ZHJhZnQ9e3todG1sIC5DaGFuZ2UuRHJhZnR9fSI+e3todG1sIC5DaGFuZ2UuRHJhZnR9fTwvYT4sIG9wZW5lZAogICAgICB7ey5DaGFuZ2UuT3BlbmVkLkZvcm1hdCAiMjAwNi0wMS0wMiAxNTowNCJ9fS4KICAgICAge3tpZiBlcSAuQ2hhbmdlLlN0YXRlICJtZXJnZWQifX1NZXJnZWQgYnkge3todG1sIC5DaGFuZ2UuQ2xvc2VkQnl9fSB7ey5DaGFuZ2UuQ2xvc2VkLkZvcm1hdCAiMjAwNi0wMS0wMiAxNTowNCJ9fS57e2VuZH19CiAgICAgIHt7aWYgZXEgLkNoYW5nZS5TdGF0ZSAiY2xvc2VkIn19Q2xvc2VkIGJ5IHt7aHRtbCAuQ2hhbmdlLkNsb3NlZEJ5fX0ge3suQ2hhbmdlLkNsb3NlZC5Gb3JtYXQgIjIwMDYtMDEtMDIgMTU6MDQifX0ue3tlbmR9fQogICAgPC9wPgoKICAgIHt7aWYgLkVycm9yfX0KICAgIDxkaXYgY2xhc3M9ImFsZXJ0IGFsZXJ0LWRhbmdlciI+e3todG1sIC5FcnJvcn19PC9kaXY+CiAgICB7e2VuZH19CgogICAge3tpZiAuRGlmZn19CiAgICA8ZGl2IGNsYXNzPSJ0YWJsZS1yZXNwb25zaXZlIj4KICAgICAgPHRhYmxlIGNsYXNzPSJ0YWJsZSB0YWJsZS1jb25kZW5zZWQiPgogICAgICAgIDx0Ym9keT4KICAgICAgICB7e3JhbmdlIC5EaWZmfX0KICAgICAgICAgIDx0ciBjbGFzcz0ie3suQ2xhc3N9fSI+PHRkIHN0eWxlPSJmb250LWZhbWlseTogbW9ub3NwYWNlOyB3aGl0ZS1zcGFjZTogcHJlIj57e2h0bWwgLlRleHR9fTwvdGQ+PC90cj4KICAgICAgICB7e2VuZH19CiAgICAgICAgPC90Ym9keT4KICAgICAgPC90YWJsZT4KICAgIDwvZGl2PgogICAge3tlbmR9fQoKICAgIHt7aWYgLkNhbk1lcmdlfX0KICAgIDxmb3JtIHJvbGU9ImZvcm0iIGFjdGlvbj0iL2NoYW5nZXMve3suQ2hhbmdlLklEfX0vbWVyZ2UiIG1ldGhvZD0iUE9TVCIgc3R5bGU9ImRpc3BsYXk6IGlubGluZSI+CiAgICAgIDxidXR0b24gdHlwZT0ic3VibWl0IiBjbGFzcz0iYnRuIGJ0bi1wcmltYXJ5Ij5NZXJnZTwvYnV0dG9uPgogICAgPC9mb3JtPgogICAge3tlbmR9fQogICAge3tpZiAuQ2FuQ2xvc2V9fQogICAgPGZvcm0gcm9sZT0iZm9ybSIgYWN0aW9uPSIvY2hhbmdlcy97ey5DaGFuZ2UuSUR9fS9jbG9zZSIgbWV0aG9kPSJQT1NUIiBzdHlsZT0iZGlzcGxheTogaW5saW5lIj4KICAgICAgPGJ1dHRvbiB0eXBlPSJzdWJtaXQiIGNsYXNzPSJidG4gYnRuLWRlZmF1bHQiPkNsb3NlPC9idXR0b24+CiAgICA8L2Zvcm0+CiAgICB7e2VuZH19Cgp7e3RlbXBsYXRlICJmb290ZXIifX0Ke3tlbmR9fQo=
 `,
-	"templates/history.html": `e3tkZWZpbmUgImhpc3RvcnkifX0Ke3t0ZW1wbGF0ZSAiaGVhZGVyIiAufX0KCiAgICA8aDE+UmV2aXNpb24gaGlzdG9yeSBmb3Ige3suVGl0bGV9fTwvaDE+CiAgICA8ZGl2IGNsYXNzPSJ0YWJsZS1yZXNwb25zaXZlIj4KICAgICAgPHRhYmxlIGNsYXNzPSJ0YWJsZSB0YWJsZS1zdHJpcGVkIj4KICAgICAgICA8dGhlYWQ+CiAgICAgICAgICA8dGg+T2JqZWN0PC90aD4KICAgICAgICAgIDx0aD5EZXNjcmlwdGlvbjwvdGg+CiAgICAgICAgICA8dGg+QXV0aG9yPC90aD4KICAgICAgICAgIDx0aD5UaW1lc3RhbXA8L3RoPgogICAgICAgIDwvdGhlYWQ+CiAgICAgICAgPHRib2R5PgogICAgICAgIHt7cmFuZ2UgLlJldmlzaW9uc319CiAgICAgICAgICA8dHI+CiAgICAgICAgICAgIDx0ZD48YSBocmVmPSIvdmlldy97ey5UaXRsZX19P3JldmlzaW9uPXt7Lk9iamVjdH19Ij57ey5PYmplY3R9fTwvdGQ+CiAgICAgICAgICAgIDx0ZD57ey5EZXNjcmlwdGlvbn19PC90ZD4KICAgICAgICAgICAgPHRkPnt7LkF1dGhvci5OYW1lfX08L3RkPgogICAgICAgICAgICA8dGQ+e3suVGltZXN0YW1wfX08L3RkPgogICAgICAgICAgPC90cj4KICAgICAgICB7e2VuZH19CiAgICAgICAgPC90Ym9keT4KICAgICAgPC90YWJsZT4KICAgIDwvZGl2PgoKe3t0ZW1wbGF0ZSAiZm9vdGVyIn19Cnt7ZW5kfX0K
+	"templates/changes.html": `e3tkZWZpbmUgImNoYW5nZXMifX0Ke3t0ZW1wbGF0ZSAiaGVhZGVyIiAufX0KCiAgICA8aDE+Q2hhbmdlIHJlcXVlc3RzPC9oMT4KCiAgICB7e2lmIC5FcnJvcn19CiAgICA8ZGl2IGNsYXNzPSJhbGVydCBhbGVydC1kYW5nZXIiPnt7aHRtbCAuRXJyb3J9fTwvZGl2PgogICAge3tlbmR9fQoKICAgIDxkaXYgY2xhc3M9InRhYmxlLXJlc3BvbnNpdmUiPgogICAgICA8dGFibGUgY2xhc3M9InRhYmxlIHRhYmxlLXN0cmlwZWQiPgogICAgICAgIDx0aGVhZD4KICAgICAgICAgIDx0aD4jPC90aD4KICAgICAgICAgIDx0aD5TdW1tYXJ5PC90aD4KICAgICAgICAgIDx0aD5EcmFmdDwvdGg+CiAgICAgICAgICA8dGg+QXV0aG9yPC90aD4KICAgICAgICAgIDx0aD5PcGVuZWQ8L3RoPgogICAgICAgICAgPHRoPlN0YXRlPC90aD4KICAgICAgICA8L3RoZWFkPgogICAgICAgIDx0Ym9keT4KICAgICAgICB7e3JhbmdlIC5DaGFuZ2VzfX0KICAgICAgICAgIDx0cj4KICAgICAgICAgICAgPHRkPjxhIGhyZWY9Ii9jaGFuZ2VzL3t7LklEfX0iPnt7LklEfX08L2E+PC90ZD4KICAgICAgICAgICAgPHRkPjxhIGhyZWY9Ii9jaGFuZ2VzL3t7LklEfX0iPnt7aHRtbCAuU3VtbWFyeX19PC9hPjwvdGQ+CiAgICAgICAgICAgIDx0ZD57e2h0bWwgLkRyYWZ0fX08L3RkPgogICAgICAgICAgICA8dGQ+e3todG1sIC5BdXRob3IuTmFtZX19PC90ZD4KICAgICAgICAgICAgPHRkPnt7Lk9wZW5lZC5Gb3JtYXQgIjIwMDYtMDEtMDIgMTU6MDQifX08L3RkPgogICAgICAgICAgICA8dGQ+e3suU3RhdGV9fTwvdGQ+CiAgICAgICAgICA8L3RyPgogICAgICAgIHt7ZW5kfX0KICAgICAgICA8L3Rib2R5PgogICAgICA8L3RhYmxlPgogICAgPC9kaXY+CgogICAgPGgyPk9wZW4gYSBjaGFuZ2UgcmVxdWVzdDwvaDI+CgogICAgPGZvcm0gcm9sZT0iZm9ybSIgYWN0aW9uPSIvY2hhbmdlcy8iIG1ldGhvZD0iUE9TVCI+CiAgICAgIDxkaXYgY2xhc3M9ImZvcm0tZ3JvdXAiPgogICAgICAgIDxpbnB1dCBuYW1lPSJkcmFmdCIgY2xhc3M9ImZvcm0tY29udHJvbCIgdHlwZT0idGV4dCIgcGxhY2Vob2xkZXI9IkRyYWZ0IiB2YWx1ZT0ie3todG1sIC5EcmFmdH19Ij4KICAgICAgPC9kaXY+CiAgICAgIDxkaXYgY2xhc3M9ImZvcm0tZ3JvdXAiPgogICAgICAgIDxpbnB1dCBuYW1lPSJzdW1tYXJ5IiBjbGFzcz0iZm9ybS1jb250cm9sIiB0eXBlPSJ0ZXh0IiBwbGFjZWhvbGRlcj0iU3VtbWFyeSI+CiAgICAgIDwvZGl2PgogICAgICA8YnV0dG9uIHR5cGU9InN1Ym1pdCIgY2xhc3M9ImJ0biBidG4tZGVmYXVsdCI+T3BlbjwvYnV0dG9uPgogICAgPC9mb3JtPgoKe3t0ZW1wbGF0ZSAiZm9vdGVyIn19Cnt7ZW5kfX0K
+`,
+	"templates/edit.html": `e3tkZWZpbmUgImVkaXQifX0Ke3t0ZW1wbGF0ZSAiaGVhZGVyIiAufX0KCiAgICA8aDE+RWRpdGluZyB7e2h0bWwgLk5hbWV9fTwvaDE+CgogICAge3tpZiAuTG9ja319CiAgICA8ZGl2IGNsYXNzPSJhbGVydCBhbGVydC13YXJuaW5nIj4KICAgICAgPHA+PHN0cm9uZz57e2h0bWwgLkxvY2suTmFtZX19PC9zdHJvbmc+IGlzIGVkaXRpbmcgc2luY2Uge3suTG9jay5TaW5jZS5Gb3JtYXQgIjE1OjA0In19LjwvcD4KICAgICAgPHA+SWYgeW91IHRha2Ugb3Zlciwgd2hvZXZlciBzYXZlcyBsYXN0IG92ZXJ3cml0ZXMgdGhlIGNoYW5nZXMgb2YgdGhlIG90aGVyLjwvcD4KICAgICAgPHA+PGEgaHJlZj0iL2VkaXQve3suVGl0bGV9fT90YWtlb3Zlcj0xIiBjbGFzcz0iYnRuIGJ0bi13YXJuaW5nIj5UYWtlIG92ZXI8L2E+PC9wPgogICAgPC9kaXY+CiAgICB7e2Vsc2V9fQogICAgPGRpdiBpZD0ibG9jay1sb3N0IiBjbGFzcz0iYWxlcnQgYWxlcnQtd2FybmluZyIgc3R5bGU9ImRpc3BsYXk6IG5vbmUiPjwvZGl2PgoKICAgIHt7aWYgLkF1dG9zYXZlfX0KICAgIDxkaXYgaWQ9ImF1dG9zYXZlIiBjbGFzcz0iYWxlcnQgYWxlcnQtaW5mbyI+CiAgICAgIDxwPkNoYW5nZXMgeW91IG1hZGUgb24ge3suQXV0b3NhdmUuU2F2ZWQuRm9ybWF0ICJKYW4gMiBhdCAxNTowNCJ9fSB3ZXJlIG5vdCBzYXZlZC48L3A+CiAgICAgIDx0ZXh0YXJlYSBpZD0iYXV0b3NhdmVkIiBzdHlsZT0iZGlzcGxheTogbm9uZSI+e3todG1sIC5BdXRvc2F2ZS5Cb2R5fX08L3RleHRhcmVhPgogICAgICA8cD4KICAgICAgICA8YnV0dG9uIHR5cGU9ImJ1dHRvbiIgaWQ9ImF1dG9zYXZlLXJlc3RvcmUiIGNsYXNzPSJidG4gYnRuLWluZm8iPlJlc3RvcmUgdGhlbTwvYnV0dG9uPgogICAgICAgIDxidXR0b24gdHlwZT0iYnV0dG9uIiBpZD0iYXV0b3NhdmUtZGlzY2FyZCIgY2xhc3M9ImJ0biBidG4tZGVmYXVsdCI+RGlzY2FyZCB0aGVtPC9idXR0b24+CiAgICAgIDwvcD4KICAgIDwvZGl2PgogICAge3tlbmR9fQoKICAgIHt7aWYgLlRlbXBsYXRlc319CiAgICA8Zm9ybSByb2xlPSJmb3JtIiBhY3Rpb249Ii9lZGl0L3t7LlRpdGxlfX0iIG1ldGhvZD0iR0VUIiBjbGFzcz0iZm9ybS1pbmxpbmUiPgogICAgICA8aW5wdXQgdHlwZT0iaGlkZGVuIiBuYW1lPSJ0aXRsZSIgdmFsdWU9Int7aHRtbCAuTmFtZX19Ij4KICAgICAgPGRpdiBjbGFzcz0iZm9ybS1ncm91cCBjb2wtbWQtMTIiPgogICAgICAgIDxzZWxlY3QgbmFtZT0idGVtcGxhdGUiIGNsYXNzPSJmb3JtLWNvbnRyb2wiPgogICAgICAgICAgPG9wdGlvbiB2YWx1ZT0iIj5FbXB0eSBwYWdlPC9vcHRpb24+CiAgICAgICAgICB7e3JhbmdlIC5UZW1wbGF0ZXN9fQogICAgICAgICAgPG9wdGlvbiB2YWx1ZT0ie3todG1sIC59fSJ7e2lmIGVxIC4gJC5UZW1wbGF0ZX19IHNlbGVjdGVke3tlbmR9fT57e2h0bWwgLn19PC9vcHRpb24+CiAgICAgICAgICB7e2VuZH19CiAgICAgICAgPC9zZWxlY3Q+CiAgICAgICAgPGJ1dHRvbiB0eXBlPSJzdWJtaXQiIGNsYXNzPSJidG4gYnRuLWRlZmF1bHQiPlN0YXJ0IGZyb20gdGVtcGxhdGU8L2J1dHRvbj4KICAgICAgPC9kaXY+CiAgICA8L2Zvcm0+CiAgICB7e2VuZH19CgogICAgPGZvcm0gcm9sZT0iZm9ybSIgYWN0aW9uPSIvc2F2ZS97ey5UaXRsZX19IiBtZXRob2Q9IlBPU1QiPgogICAgICA8ZGl2IGNsYXNzPSJmb3JtLWdyb3VwIGNvbC1tZC02Ij4KICAgICAgICA8dGV4dGFyZWEgaWQ9ImJvZHkiIG5hbWU9ImJvZHkiIGNsYXNzPSJmb3JtLWNvbnRyb2wiIHJvd3M9IjIwIj57ey5Cb2R5fX08L3RleHRhcmVhPgogICAgICA8L2Rpdj4KICAgICAgPGRpdiBjbGFzcz0iY29sLW1kLTYiPgogICAgICAgIDxkaXYgaWQ9InByZXZpZXciPjwvZGl2PgogICAgICA8L2Rpdj4KICAgICAgPGRpdiBjbGFzcz0iZm9ybS1ncm91cCBjb2wtbWQtMTIiPgogICAgICAgIDxpbnB1dCBuYW1lPSJkZXNjcmlwdGlvbiIgY2xhc3M9ImZvcm0tY29udHJvbCIgdHlwZT0idGV4dCIgcGxhY2Vob2xkZXI9IlVwZGF0ZSB7e2h0bWwgLk5hbWV9fSI+CiAgICAgIDwvZGl2PgogICAgICB7e2lmIC5EcmFmdHN9fQogICAgICA8ZGl2IGNsYXNzPSJmb3JtLWdyb3VwIGNvbC1tZC0xMiI+CiAgICAgICAgPGlucHV0IG5hbWU9ImRyYWZ0IiBjbGFzcz0iZm9ybS1jb250cm9sIiB0eXBlPSJ0ZXh0IiBwbGFjZWhvbGRlcj0iRHJhZnQgbmFtZSwgdG8gc2F2ZSBmb3IgcmV2aWV3IGluc3RlYWQgb2YgcHVibGlzaGluZyIgdmFsdWU9Int7aHRtbCAuRHJhZnR9fSI+CiAgICAgIDwvZGl2PgogICAgICB7e2VuZH19CiAgICAgIDxkaXYgY2xhc3M9ImZvcm0tZ3JvdXAgY29sLW1kLTEyIj4KICAgICAgICA8YnV0dG9uIHR5cGU9InN1Ym1pdCIgY2xhc3M9ImJ0biBidG4tZGVmYXVsdCI+U2F2ZTwvYnV0dG9uPgogICAgICA8L2Rpdj4KICAgIDwvZm9ybT4KICAgIDxzY3JpcHQ+CiAgICAgIChmdW5jdGlvbigpIHsKICAgICAgICB2YXIgYm9keSA9IGRvY3VtZW50LmdldEVsZW1lbnRCeUlkKCJib2R5Iik7CiAgICAgICAgdmFyIHByZXZpZXcgPSBkb2N1bWVudC5nZXRFbGVtZW50QnlJZCgicHJldmlldyIpOwogICAgICAgIHZhciB0aW1lcjsKICAgICAgICBmdW5jdGlvbiB1cGRhdGUoKSB7CiAgICAgICAgICB2YXIgeGhyID0gbmV3IFhNTEh0dHBSZXF1ZXN0KCk7CiAgICAgICAgICB4aHIub3BlbigiUE9TVCIsICIvcHJldmlldy97ey5UaXRsZX19Iik7CiAgICAgICAgICB4aHIuc2V0UmVxdWVzdEhlYWRlcigiQ29udGVudC1UeXBlIiwgImFwcGxpY2F0aW9uL3gtd3d3LWZvcm0tdXJsZW5jb2RlZCIpOwogICAgICAgICAgeGhyLm9ubG9hZCA9IGZ1bmN0aW9uKCkgewogICAgICAgICAgICBpZiAoeGhyLnN0YXR1cyA9PSAyMDApIHsKICAgICAgICAgICAgICBwcmV2aWV3LmlubmVySFRNTCA9IHhoci5yZXNwb25zZVRleHQ7CiAgICAgICAgICAgIH0KICAgICAgICAgIH07CiAgICAgICAgICB4aHIuc2VuZCgiYm9keT0iICsgZW5jb2RlVVJJQ29tcG9uZW50KGJvZHkudmFsdWUpKTsKICAgICAgICB9CiAgICAgICAgYm9keS5hZGRFdmVudExpc3RlbmVyKCJpbnB1dCIsIGZ1bmN0aW9uKCkgewogICAgICAgICAgY2xlYXJUaW1lb3V0KHRpbWVyKTsKICAgICAgICAgIHRpbWVyID0gc2V0VGltZW91dCh1cGRhdGUsIDMwMCk7CiAgICAgICAgfSk7CiAgICAgICAgdXBkYXRlKCk7CiAgICAgIH0pKCk7CiAgICA8L3NjcmlwdD4KICAgIHt7aWYgLkF1dG9zYXZlSW50ZXJ2YWx9fQogICAgPHNjcmlwdD4KICAgICAgKGZ1bmN0aW9uKCkgewogICAgICAgIHZhciBib2R5ID0gZG9jdW1lbnQuZ2V0RWxlbWVudEJ5SWQoImJvZHkiKTsKICAgICAgICB2YXIgYXV0b3NhdmUgPSAiL2F1dG9zYXZlL3t7LlRpdGxlfX0iOwogICAgICAgIHZhciBzYXZlZCA9IGJvZHkudmFsdWU7CiAgICAgICAgc2V0SW50ZXJ2YWwoZnVuY3Rpb24oKSB7CiAgICAgICAgICB2YXIgdmFsdWUgPSBib2R5LnZhbHVlOwogICAgICAgICAgaWYgKHZhbHVlID09IHNhdmVkKSB7CiAgICAgICAgICAgIHJldHVybjsKICAgICAgICAgIH0KICAgICAgICAgIHZhciB4aHIgPSBuZXcgWE1MSHR0cFJlcXVlc3QoKTsKICAgICAgICAgIHhoci5vcGVuKCJQT1NUIiwgYXV0b3NhdmUpOwogICAgICAgICAgeGhyLnNldFJlcXVlc3RIZWFkZXIoIkNvbnRlbnQtVHlwZSIsICJhcHBsaWNhdGlvbi94LXd3dy1mb3JtLXVybGVuY29kZWQiKTsKICAgICAgICAgIHhoci5vbmxvYWQgPSBmdW5jdGlvbigpIHsKICAgICAgICAgICAgaWYgKHhoci5zdGF0dXMgPT0gMjA0KSB7CiAgICAgICAgICAgICAgc2F2ZWQgPSB2YWx1ZTsKICAgICAgICAgICAgfQogICAgICAgICAgfTsKICAgICAgICAgIHhoci5zZW5kKCJib2R5PSIgKyBlbmNvZGVVUklDb21wb25lbnQodmFsdWUpKTsKICAgICAgICB9LCB7ey5BdXRvc2F2ZUludGVydmFsfX0pOwoKICAgICAgICB2YXIgb2ZmZXIgPSBkb2N1bWVudC5nZXRFbGVtZW50QnlJZCgiYXV0b3NhdmUiKTsKICAgICAgICBpZiAob2ZmZXIpIHsKICAgICAgICAgIGRvY3VtZW50LmdldEVsZW1lbnRCeUlkKCJhdXRvc2F2ZS1yZXN0b3JlIikuYWRkRXZlbnRMaXN0ZW5lcigiY2xpY2siLCBmdW5jdGlvbigpIHsKICAgICAgICAgICAgYm9keS52YWx1ZSA9IGRvY3VtZW50LmdldEVsZW1lbnRCeUlkKCJhdXRvc2F2ZWQiKS52YWx1ZTsKICAgICAgICAgICAgYm9keS5kaXNwYXRjaEV2ZW50KG5ldyBFdmVudCgiaW5wdXQiKSk7CiAgICAgICAgICAgIG9mZmVyLnN0eWxlLmRpc3BsYXkgPSAibm9uZSI7CiAgICAgICAgICB9KTsKICAgICAgICAgIGRvY3VtZW50LmdldEVsZW1lbnRCeUlkKCJhdXRvc2F2ZS1kaXNjYXJkIikuYWRkRXZlbnRMaXN0ZW5lcigiY2xpY2siLCBmdW5jdGlvbigpIHsKICAgICAgICAgICAgdmFyIHhociA9IG5ldyBYTUxIdHRwUmVxdWVzdCgpOwogICAgICAgICAgICB4aHIub3BlbigiREVMRVRFIiwgYXV0b3NhdmUpOwogICAgICAgICAgICB4aHIuc2VuZCgpOwogICAgICAgICAgICBvZmZlci5zdHlsZS5kaXNwbGF5ID0gIm5vbmUiOwogICAgICAgICAgfSk7CiAgICAgICAgfQogICAgICB9KSgpOwogICAgPC9zY3JpcHQ+CiAgICB7e2VuZH19CiAgICB7e2lmIC5IZWFydGJlYXR9fQogICAgPHNjcmlwdD4KICAgICAgKGZ1bmN0aW9uKCkgewogICAgICAgIHZhciBsb2NrID0gIi9sb2NrL3t7LlRpdGxlfX0iOwogICAgICAgIHNldEludGVydmFsKGZ1bmN0aW9uKCkgewogICAgICAgICAgdmFyIHhociA9IG5ldyBYTUxIdHRwUmVxdWVzdCgpOwogICAgICAgICAgeGhyLm9wZW4oIlBPU1QiLCBsb2NrKTsKICAgICAgICAgIHhoci5vbmxvYWQgPSBmdW5jdGlvbigpIHsKICAgICAgICAgICAgaWYgKHhoci5zdGF0dXMgPT0gNDA5KSB7CiAgICAgICAgICAgICAgdmFyIGhvbGRlciA9IEpTT04ucGFyc2UoeGhyLnJlc3BvbnNlVGV4dCk7CiAgICAgICAgICAgICAgdmFyIGxvc3QgPSBkb2N1bWVudC5nZXRFbGVtZW50QnlJZCgibG9jay1sb3N0Iik7CiAgICAgICAgICAgICAgbG9zdC50ZXh0Q29udGVudCA9IGhvbGRlci5OYW1lICsgIiB0b29rIG92ZXIgZWRpdGluZyBhdCAiICsgaG9sZGVyLlNpbmNlICsgIi4gU2F2aW5nIG92ZXJ3cml0ZXMgdGhlaXIgY2hhbmdlcy4iOwogICAgICAgICAgICAgIGxvc3Quc3R5bGUuZGlzcGxheSA9ICJibG9jayI7CiAgICAgICAgICAgIH0KICAgICAgICAgIH07CiAgICAgICAgICB4aHIuc2VuZCgpOwogICAgICAgIH0sIHt7LkhlYXJ0YmVhdH19KTsKICAgICAgICB3aW5kb3cuYWRkRXZlbnRMaXN0ZW5lcigicGFnZWhpZGUiLCBmdW5jdGlvbigpIHsKICAgICAgICAgIGlmICh3aW5kb3cuZmV0Y2gpIHsKICAgICAgICAgICAgZmV0Y2gobG9jaywge21ldGhvZDogIkRFTEVURSIsIGNyZWRlbnRpYWxzOiAic2FtZS1vcmlnaW4iLCBrZWVwYWxpdmU6IHRydWV9KTsKICAgICAgICAgIH0KICAgICAgICB9KTsKICAgICAgfSkoKTsKICAgIDwvc2NyaXB0PgogICAge3tlbmR9fQogICAge3tlbmR9fQoKe3t0ZW1wbGF0ZSAiZm9vdGVyIn19Cnt7ZW5kfX0K
+`,
+	"templates/history.html": `e3tkZWZpbmUgImhpc3RvcnkifX0Ke3t0ZW1wbGF0ZSAiaGVhZGVyIiAufX0KCiAgICA8aDE+UmV2aXNpb24gaGlzdG9yeSBmb3Ige3todG1sIC5OYW1lfX08L2gxPgogICAge3tpZiAuTm9IaXN0b3J5fX0KICAgIDxwPlRoaXMgd2lraSBrZWVwcyBubyBwYWdlIGhpc3RvcnkuPC9wPgogICAge3tlbHNlfX0KICAgIDxkaXYgY2xhc3M9InRhYmxlLXJlc3BvbnNpdmUiPgogICAgICA8dGFibGUgY2xhc3M9InRhYmxlIHRhYmxlLXN0cmlwZWQiPgogICAgICAgIDx0aGVhZD4KICAgICAgICAgIDx0aD5PYmplY3Q8L3RoPgogICAgICAgICAgPHRoPkRlc2NyaXB0aW9uPC90aD4KICAgICAgICAgIDx0aD5BdXRob3I8L3RoPgogICAgICAgICAgPHRoPlRpbWVzdGFtcDwvdGg+CiAgICAgICAgPC90aGVhZD4KICAgICAgICA8dGJvZHk+CiAgICAgICAge3tyYW5nZSAuUmV2aXNpb25zfX0KICAgICAgICAgIDx0cj4KICAgICAgICAgICAgPHRkPjxhIGhyZWY9Ii92aWV3L3t7LlRpdGxlfX0/cmV2aXNpb249e3suT2JqZWN0fX0iPnt7Lk9iamVjdH19PC90ZD4KICAgICAgICAgICAgPHRkPnt7LkRlc2NyaXB0aW9ufX08L3RkPgogICAgICAgICAgICA8dGQ+e3suQXV0aG9yLk5hbWV9fTwvdGQ+CiAgICAgICAgICAgIDx0ZD57ey5UaW1lc3RhbXB9fTwvdGQ+CiAgICAgICAgICA8L3RyPgogICAgICAgIHt7ZW5kfX0KICAgICAgICA8L3Rib2R5PgogICAgICA8L3RhYmxlPgogICAgPC9kaXY+CiAgICB7e2VuZH19Cgp7e3RlbXBsYXRlICJmb290ZXIifX0Ke3tlbmR9fQo=
 `,
 	"templates/search.html": `e3tkZWZpbmUgInNlYXJjaCJ9fQp7e3RlbXBsYXRlICJoZWFkZXIiIC59fQoKICAgIDxoMT5TZWFyY2ggUmVzdWx0czwvaDE+CiAgICAKICAgIDx1bD4KICAgICAge3tyYW5nZSAuUmVzdWx0c319CiAgICAgIDxsaT48YSBocmVmPSIvdmlldy97ey5UaXRsZX19Ij57ey5UaXRsZX19PC9hPiAtIHt7LkNvbnRlbnR9fTwvbGk+CiAgICAgIHt7ZW5kfX0KICAgIDwvdWw+Cgp7e3RlbXBsYXRlICJmb290ZXIifX0Ke3tlbmR9fQo=
 `,
-	"templates/upload.html": `e3tkZWZpbmUgImVkaXQifX0Ke3t0ZW1wbGF0ZSAiaGVhZGVyIiAufX0KCiAgICA8aDE+VXBsb2FkIEZpbGU8L2gxPgoKICAgIDxmb3JtIHJvbGU9ImZvcm0iIGFjdGlvbj0iL3VwbG9hZC97ey5UaXRsZX19IiBtZXRob2Q9IlBPU1QiPgogICAgICA8ZGl2IGNsYXNzPSJmb3JtLWdyb3VwIGNvbC1tZC0xMiI+CiAgICAgICAgPGlucHV0IG5hbWU9ImZpbGUiIGNsYXNzPSJmb3JtLWNvbnRyb2wiIHJvd3M9IjgiPgogICAgICA8L2Rpdj4KICAgICAgPGRpdiBjbGFzcz0iZm9ybS1ncm91cCBjb2wtbWQtMTIiPgogICAgICAgIDxpbnB1dCBuYW1lPSJkZXNjcmlwdGlvbiIgY2xhc3M9ImZvcm0tY29udHJvbCIgdHlwZT0idGV4dCIgcGxhY2Vob2xkZXI9IlVwZGF0ZSB7ey5UaXRsZX19Ij4KICAgICAgPC9kaXY+CiAgICAgIDxkaXYgY2xhc3M9ImZvcm0tZ3JvdXAgY29sLW1kLTEyIj4KICAgICAgICA8YnV0dG9uIHR5cGU9InN1Ym1pdCIgY2xhc3M9ImJ0biBidG4tZGVmYXVsdCI+U2F2ZTwvYnV0dG9uPgogICAgICA8L2Rpdj4KICAgIDwvZm9ybT4KCnt7dGVtcGxhdGUgImZvb3RlciJ9fQp7e2VuZH19Cg==
+	"templates/sync.html": `e3tkZWZpbmUgInN5bmMifX0Ke3t0ZW1wbGF0ZSAiaGVhZGVyIiAufX0KCiAgICA8aDE+U3luY2hyb25pemF0aW9uPC9oMT4KCiAgICA8ZGl2IGNsYXNzPSJ0YWJsZS1yZXNwb25zaXZlIj4KICAgICAgPHRhYmxlIGNsYXNzPSJ0YWJsZSB0YWJsZS1zdHJpcGVkIj4KICAgICAgICA8dGJvZHk+CiAgICAgICAgICA8dHI+CiAgICAgICAgICAgIDx0aD5SZW1vdGU8L3RoPgogICAgICAgICAgICA8dGQ+e3todG1sIC5SZW1vdGV9fSB7e2h0bWwgLkJyYW5jaH19PC90ZD4KICAgICAgICAgIDwvdHI+CiAgICAgICAgICA8dHI+CiAgICAgICAgICAgIDx0aD5TdHJhdGVneTwvdGg+CiAgICAgICAgICAgIDx0ZD57e2h0bWwgLlN0cmF0ZWd5fX0sIGV2ZXJ5IHt7LkludGVydmFsfX08L3RkPgogICAgICAgICAgPC90cj4KICAgICAgICAgIDx0cj4KICAgICAgICAgICAgPHRoPkxhc3QgcHVsbDwvdGg+CiAgICAgICAgICAgIDx0ZD57e2lmIC5MYXN0UHVsbC5Jc1plcm99fW5ldmVye3tlbHNlfX17ey5MYXN0UHVsbC5Gb3JtYXQgIjIwMDYtMDEtMDIgMTU6MDQ6MDUifX17e2VuZH19PC90ZD4KICAgICAgICAgIDwvdHI+CiAgICAgICAgICA8dHI+CiAgICAgICAgICAgIDx0aD5MYXN0IHB1c2g8L3RoPgogICAgICAgICAgICA8dGQ+e3tpZiAuTGFzdFB1c2guSXNaZXJvfX1uZXZlcnt7ZWxzZX19e3suTGFzdFB1c2guRm9ybWF0ICIyMDA2LTAxLTAyIDE1OjA0OjA1In19e3tlbmR9fTwvdGQ+CiAgICAgICAgICA8L3RyPgogICAgICAgIDwvdGJvZHk+CiAgICAgIDwvdGFibGU+CiAgICA8L2Rpdj4KCiAgICB7e2lmIC5QdWxsRXJyb3J9fQogICAgPGRpdiBjbGFzcz0iYWxlcnQgYWxlcnQtZGFuZ2VyIj48c3Ryb25nPlB1bGwgZmFpbGVkOjwvc3Ryb25nPiA8cHJlPnt7aHRtbCAuUHVsbEVycm9yfX08L3ByZT48L2Rpdj4KICAgIHt7ZW5kfX0KICAgIHt7aWYgLlB1c2hFcnJvcn19CiAgICA8ZGl2IGNsYXNzPSJhbGVydCBhbGVydC1kYW5nZXIiPjxzdHJvbmc+UHVzaCBmYWlsZWQ6PC9zdHJvbmc+IDxwcmU+e3todG1sIC5QdXNoRXJyb3J9fTwvcHJlPjwvZGl2PgogICAge3tlbmR9fQogICAge3tpZiAuQ29uZmxpY3RzfX0KICAgIDxkaXYgY2xhc3M9ImFsZXJ0IGFsZXJ0LXdhcm5pbmciPgogICAgICA8cD5UaGUgbGFzdCBwdWxsIHdhcyBhYm9ydGVkIGJlY2F1c2Ugb2YgY29uZmxpY3RzIGluIHRoZXNlIHBhZ2VzLiBSZXNvbHZlIHRoZSBjb25mbGljdHMgaW4gdGhlIHJlcG8sIHRoZW4gc3luY2hyb25pemUgYWdhaW4uPC9wPgogICAgICA8dWw+CiAgICAgICAge3tyYW5nZSAuQ29uZmxpY3RzfX0KICAgICAgICA8bGk+e3todG1sIC59fTwvbGk+CiAgICAgICAge3tlbmR9fQogICAgICA8L3VsPgogICAgPC9kaXY+CiAgICB7e2VuZH19CgogICAgPGZvcm0gcm9sZT0iZm9ybSIgYWN0aW9uPSIvYWRtaW4vc3luYyIgbWV0aG9kPSJQT1NUIj4KICAgICAgPGJ1dHRvbiB0eXBlPSJzdWJtaXQiIGNsYXNzPSJidG4gYnRuLWRlZmF1bHQiPlN5bmNocm9uaXplIG5vdzwvYnV0dG9uPgogICAgPC9mb3JtPgoKe3t0ZW1wbGF0ZSAiZm9vdGVyIn19Cnt7ZW5kfX0K
+`,
+	"templates/twofactor.html": `e3tkZWZpbmUgInR3b2ZhY3RvciJ9fQp7e3RlbXBsYXRlICJoZWFkZXIiIC59fQoKICAgIDxoMT5Ud28tZmFjdG9yIGF1dGhlbnRpY2F0aW9uPC9oMT4KCiAgICB7e2lmIC5FcnJvcn19CiAgICA8ZGl2IGNsYXNzPSJhbGVydCBhbGVydC1kYW5nZXIiPnt7aHRtbCAuRXJyb3J9fTwvZGl2PgogICAge3tlbmR9fQoKICAgIHt7aWYgZXEgLk1vZGUgImVucm9sbCJ9fQogICAgPHA+U2NhbiB0aGUgY29kZSBiZWxvdyB3aXRoIGFuIGF1dGhlbnRpY2F0b3IgYXBwLCBvciBlbnRlciB0aGUga2V5IDxjb2RlPnt7LlNlY3JldH19PC9jb2RlPiBtYW51YWxseS4gVGhlbiBlbnRlciB0aGUgY29kZSB0aGUgYXBwIGRpc3BsYXlzIHRvIGNvbmZpcm0uPC9wPgogICAgPGRpdj57ey5RUkNvZGV9fTwvZGl2PgogICAgPGZvcm0gcm9sZT0iZm9ybSIgYWN0aW9uPSIvMmZhL2Vucm9sbCIgbWV0aG9kPSJQT1NUIj4KICAgICAgPGlucHV0IHR5cGU9ImhpZGRlbiIgbmFtZT0ibmV4dCIgdmFsdWU9Int7aHRtbCAuTmV4dH19Ij4KICAgICAgPGRpdiBjbGFzcz0iZm9ybS1ncm91cCBjb2wtbWQtNCI+CiAgICAgICAgPGlucHV0IG5hbWU9ImNvZGUiIGNsYXNzPSJmb3JtLWNvbnRyb2wiIHR5cGU9InRleHQiIGF1dG9jb21wbGV0ZT0ib2ZmIiBwbGFjZWhvbGRlcj0iMTIzNDU2IiBhdXRvZm9jdXM+CiAgICAgIDwvZGl2PgogICAgICA8ZGl2IGNsYXNzPSJmb3JtLWdyb3VwIGNvbC1tZC0xMiI+CiAgICAgICAgPGJ1dHRvbiB0eXBlPSJzdWJtaXQiIGNsYXNzPSJidG4gYnRuLWRlZmF1bHQiPkVuYWJsZTwvYnV0dG9uPgogICAgICA8L2Rpdj4KICAgIDwvZm9ybT4KICAgIHt7ZW5kfX0KCiAgICB7e2lmIGVxIC5Nb2RlICJyZWNvdmVyeSJ9fQogICAgPHA+VHdvLWZhY3RvciBhdXRoZW50aWNhdGlvbiBpcyBlbmFibGVkLiBTdG9yZSB0aGVzZSByZWNvdmVyeSBjb2RlcyBpbiBhIHNhZmUgcGxhY2UuIEVhY2ggb2YgdGhlbSBjYW4gYmUgdXNlZCBvbmNlIGluIHBsYWNlIG9mIGEgY29kZSBpZiB5b3UgbG9zZSBhY2Nlc3MgdG8geW91ciBhdXRoZW50aWNhdG9yIGFwcC48L3A+CiAgICA8dWw+CiAgICAgIHt7cmFuZ2UgLlJlY292ZXJ5Q29kZXN9fQogICAgICA8bGk+PGNvZGU+e3sufX08L2NvZGU+PC9saT4KICAgICAge3tlbmR9fQogICAgPC91bD4KICAgIDxhIGhyZWY9Int7aHRtbCAuTmV4dH19IiBjbGFzcz0iYnRuIGJ0bi1kZWZhdWx0Ij5Db250aW51ZTwvYT4KICAgIHt7ZW5kfX0KCiAgICB7e2lmIGVxIC5Nb2RlICJ2ZXJpZnkifX0KICAgIDxwPkVudGVyIHRoZSBjb2RlIGZyb20geW91ciBhdXRoZW50aWNhdG9yIGFwcCwgb3Igb25lIG9mIHlvdXIgcmVjb3ZlcnkgY29kZXMuPC9wPgogICAgPGZvcm0gcm9sZT0iZm9ybSIgYWN0aW9uPSIvMmZhL3ZlcmlmeSIgbWV0aG9kPSJQT1NUIj4KICAgICAgPGlucHV0IHR5cGU9ImhpZGRlbiIgbmFtZT0ibmV4dCIgdmFsdWU9Int7aHRtbCAuTmV4dH19Ij4KICAgICAgPGRpdiBjbGFzcz0iZm9ybS1ncm91cCBjb2wtbWQtNCI+CiAgICAgICAgPGlucHV0IG5hbWU9ImNvZGUiIGNsYXNzPSJmb3JtLWNvbnRyb2wiIHR5cGU9InRleHQiIGF1dG9jb21wbGV0ZT0ib2ZmIiBwbGFjZWhvbGRlcj0iMTIzNDU2IiBhdXRvZm9jdXM+CiAgICAgIDwvZGl2PgogICAgICA8ZGl2IGNsYXNzPSJmb3JtLWdyb3VwIGNvbC1tZC0xMiI+CiAgICAgICAgPGJ1dHRvbiB0eXBlPSJzdWJtaXQiIGNsYXNzPSJidG4gYnRuLWRlZmF1bHQiPlZlcmlmeTwvYnV0dG9uPgogICAgICA8L2Rpdj4KICAgIDwvZm9ybT4KICAgIHt7ZW5kfX0KCiAgICB7e2lmIGVxIC5Nb2RlICJlbnJvbGxlZCJ9fQogICAgPHA+VHdvLWZhY3RvciBhdXRoZW50aWNhdGlvbiBpcyBlbmFibGVkIGZvciB5b3VyIGFjY291bnQuPC9wPgogICAge3tpZiBub3QgLlJlcXVpcmVkfX0KICAgIDxmb3JtIHJvbGU9ImZvcm0iIGFjdGlvbj0iLzJmYS9kaXNhYmxlIiBtZXRob2Q9IlBPU1QiPgogICAgICA8YnV0dG9uIHR5cGU9InN1Ym1pdCIgY2xhc3M9ImJ0biBidG4tZGFuZ2VyIj5EaXNhYmxlPC9idXR0b24+CiAgICA8L2Zvcm0+CiAgICB7e2VuZH19CiAgICB7e2VuZH19Cgp7e3RlbXBsYXRlICJmb290ZXIifX0Ke3tlbmR9fQo=
+`,
+	"templates/view.html": `e3tkZWZpbmUgInZpZXcifX0Ke3t0ZW1wbGF0ZSAiaGVhZGVyIiAufX0KCiAgICB7e2lmIC5EcmFmdH19CiAgICA8ZGl2IGNsYXNzPSJhbGVydCBhbGVydC1pbmZvIj4KICAgICAgUHJldmlld2luZyB0aGUgZHJhZnQgPHN0cm9uZz57e2h0bWwgLkRyYWZ0fX08L3N0cm9uZz4uCiAgICAgIDxhIGhyZWY9Ii9jaGFuZ2VzLz9kcmFmdD17e2h0bWwgLkRyYWZ0fX0iIGNsYXNzPSJhbGVydC1saW5rIj5PcGVuIGEgY2hhbmdlIHJlcXVlc3Q8L2E+IG9yCiAgICAgIDxhIGhyZWY9Ij9kcmFmdD0iIGNsYXNzPSJhbGVydC1saW5rIj5zdG9wIHByZXZpZXdpbmc8L2E+LgogICAgPC9kaXY+CiAgICB7e2VuZH19CgogICAge3tpZiAuUmVkaXJlY3RlZEZyb219fQogICAgPHAgY2xhc3M9InRleHQtbXV0ZWQiPihSZWRpcmVjdGVkIGZyb20gPGEgaHJlZj0iL3ZpZXcve3suUmVkaXJlY3RlZEZyb219fT9yZWRpcmVjdD1ubyI+e3todG1sIC5SZWRpcmVjdGVkRnJvbX19PC9hPik8L3A+CiAgICB7e2VuZH19CiAgICB7e2lmIC5SZWRpcmVjdFRvfX0KICAgIDxkaXYgY2xhc3M9ImFsZXJ0IGFsZXJ0LWluZm8iPgogICAgICBUaGlzIHBhZ2UgcmVkaXJlY3RzIHRvIDxhIGhyZWY9Ii92aWV3L3t7LlJlZGlyZWN0VG99fSIgY2xhc3M9ImFsZXJ0LWxpbmsiPnt7aHRtbCAuUmVkaXJlY3RUb319PC9hPi4KICAgIDwvZGl2PgogICAge3tlbmR9fQoKICAgIDxkaXY+e3suQm9keX19PC9kaXY+CgogICAge3tpZiBub3QgZXhwb3J0ZWR9fQogICAgPHNjcmlwdD4KICAgICAgKGZ1bmN0aW9uKCkgewogICAgICAgIHZhciB0YXNrcyA9IGRvY3VtZW50LnF1ZXJ5U2VsZWN0b3JBbGwoImlucHV0LnRhc2tbZGF0YS1saW5lXSIpOwogICAgICAgIEFycmF5LnByb3RvdHlwZS5mb3JFYWNoLmNhbGwodGFza3MsIGZ1bmN0aW9uKGJveCkgewogICAgICAgICAgYm94LmRpc2FibGVkID0gZmFsc2U7CiAgICAgICAgICBib3guYWRkRXZlbnRMaXN0ZW5lcigiY2hhbmdlIiwgZnVuY3Rpb24oKSB7CiAgICAgICAgICAgIHZhciB4aHIgPSBuZXcgWE1MSHR0cFJlcXVlc3QoKTsKICAgICAgICAgICAgeGhyLm9wZW4oIlBPU1QiLCAiL3Rhc2sve3suVGl0bGV9fSIpOwogICAgICAgICAgICB4aHIuc2V0UmVxdWVzdEhlYWRlcigiQ29udGVudC1UeXBlIiwgImFwcGxpY2F0aW9uL3gtd3d3LWZvcm0tdXJsZW5jb2RlZCIpOwogICAgICAgICAgICB4aHIub25sb2FkID0gZnVuY3Rpb24oKSB7CiAgICAgICAgICAgICAgaWYgKHhoci5zdGF0dXMgIT0gMjA0KSB7CiAgICAgICAgICAgICAgICBib3guY2hlY2tlZCA9ICFib3guY2hlY2tlZDsKICAgICAgICAgICAgICAgIGFsZXJ0KHhoci5yZXNwb25zZVRleHQpOwogICAgICAgICAgICAgIH0KICAgICAgICAgICAgfTsKICAgICAgICAgICAgeGhyLnNlbmQoImxpbmU9IiArIGJveC5kYXRhc2V0LmxpbmUgKyAiJnRleHQ9IiArIGVuY29kZVVSSUNvbXBvbmVudChib3guZGF0YXNldC50ZXh0KSArICImZG9uZT0iICsgYm94LmNoZWNrZWQpOwogICAgICAgICAgfSk7CiAgICAgICAgfSk7CiAgICAgIH0pKCk7CiAgICA8L3NjcmlwdD4KICAgIHt7ZW5kfX0KCnt7dGVtcGxhdGUgImZvb3RlciJ9fQp7e2VuZH19Cg==
+`,
+	"seed/help.md": `SGVscAo9PT09CgpQYWdlcyBhcmUgd3JpdHRlbiBpbiBbTWFya2Rvd25dKGh0dHA6Ly9kYXJpbmdmaXJlYmFsbC5uZXQvcHJvamVjdHMvbWFya2Rvd24vc3ludGF4KS4KRXZlcnkgc2F2ZSBpcyBjb21taXR0ZWQgdG8gR2l0LCBzbyB0aGUgX0hpc3RvcnlfIG9mIGEgcGFnZSBzaG93cyB3aG8gY2hhbmdlZAp3aGF0IGFuZCB3aGVuLgoKTGlua3MKLS0tLS0KCiAgICBbaG9tZV0oKSAgICAgICAgICAgICAgICAgICBhIGxpbmsgdG8gdGhlIHBhZ2UgImhvbWUiCiAgICBbbGlmZS9iaWN5Y2xlXSgpICAgICAgICAgICBwYWdlcyBjYW4gYmUgb3JnYW5pemVkIGluIGRpcmVjdG9yaWVzCiAgICBbR2l0XShodHRwOi8vZ2l0LXNjbS5jb20pICAgIGEgbGluayB0byBhbm90aGVyIHNpdGUKCkxpbmtpbmcgdG8gYSBwYWdlIHRoYXQgZG9lcyBub3QgZXhpc3QgeWV0IGlzIGhvdyBuZXcgcGFnZXMgYXJlIGNyZWF0ZWQ7IHN1Y2gKbGlua3MgYXJlIHNob3duIGluIHJlZC4KClBhZ2VzIGFyZSBuYW1lZCBhZnRlciB0aGUgdGl0bGUgdGhleSBhcmUgbGlua2VkIHdpdGg6IGBbQ2Fmw6kgTWVudV0oKWAgbGlua3MKdG8gdGhlIHBhZ2UgIkNhZsOpLU1lbnUiLiBBIHRpdGxlIHRoYXQgdGhlIG5hbWUgZG9lcyBub3QgdGVsbCwgbGlrZSAiUSZBIiBmb3IKdGhlIHBhZ2UgIlEtQSIsIGlzIGtlcHQgYXQgdGhlIHRvcCBvZiB0aGUgcGFnZToKCiAgICAtLS0KICAgIHRpdGxlOiBRJkEKICAgIC0tLQoKTGlua3MgaW4gdGhlIHN0eWxlIG9mIEdvbGx1bSBhbmQgR2l0SHViIHdpa2lzIHdvcmsgYXMgd2VsbDoKCiAgICBbW0JpY3ljbGUgUmVwYWlyXV0gICAgICAgICBhIGxpbmsgdG8gdGhlIHBhZ2UgIkJpY3ljbGUtUmVwYWlyIgogICAgW1tyZXBhaXJzfEJpY3ljbGUgUmVwYWlyXV0gdGhlIHNhbWUsIHJlYWRpbmcgInJlcGFpcnMiCiAgICBbW3doZWVsLnBuZ11dICAgICAgICAgICAgICBhbiBpbWFnZSBhdHRhY2hlZCB0byB0aGUgd2lraQoKQSBwYWdlIGNhbiBzZW5kIGl0cyByZWFkZXJzIG9uIHRvIGFub3RoZXIgcGFnZSwgZm9yIGluc3RhbmNlIGFmdGVyIGl0IHdhcwpyZW5hbWVkLCBieSBzdGFydGluZyB3aXRoOgoKICAgICNSRURJUkVDVCBbTmV3IE5hbWVdKCkKClBhZ2UgbmFtZXMgYXJlIG1hdGNoZWQgcmVnYXJkbGVzcyBvZiBjYXNlIGlmIHRoZXJlIGlzIG5vIGV4YWN0IG1hdGNoLgoKSW5jbHVkaW5nIFBhZ2VzCi0tLS0tLS0tLS0tLS0tLQoKU25pcHBldHMgc2hhcmVkIGJ5IG1hbnkgcGFnZXMsIGxpa2UgY29udGFjdCBsaXN0cywgYXJlIGtlcHQgb24gYSBwYWdlIG9mIHRoZWlyCm93biBhbmQgaW5jbHVkZWQgd2hlcmUgbmVlZGVkOgoKICAgIHt7aW5jbHVkZTpzaGFyZWQvb25jYWxsfX0gICAgICAgdGhlIHBhZ2UgYXMgaXQgaXMgbm93CiAgICB7e2luY2x1ZGU6c2hhcmVkL29uY2FsbEB2Mn19ICAgIHRoZSBwYWdlIGFzIGl0IHdhcyBhdCBhIHJldmlzaW9uCgpUZW1wbGF0ZXMKLS0tLS0tLS0tCgpQYWdlcyB1bmRlciBgdGVtcGxhdGVzL2AsIGxpa2UgYHRlbXBsYXRlcy9ydW5ib29rYCwgYXJlIHRlbXBsYXRlcyB0aGF0IG5ldwpwYWdlcyBjYW4gc3RhcnQgZnJvbS4gV2hlbiBhIHRlbXBsYXRlIGlzIGNob3NlbiBmb3IgYSBuZXcgcGFnZSwgYHt7ZGF0ZX19YCwKYHt7dXNlcn19YCBhbmQgYHt7dGl0bGV9fWAgaW4gaXQgYXJlIGZpbGxlZCBpbiB3aXRoIHRoZSBkYXksIHRoZSBuYW1lIG9mIHdob2V2ZXIKY3JlYXRlcyB0aGUgcGFnZSBhbmQgaXRzIHRpdGxlLgoKVGV4dAotLS0tCgogICAgKmVtcGhhc2lzKiwgKipzdHJvbmcgZW1waGFzaXMqKiBhbmQgYGNvZGVgCgogICAgSGVhZGluZwogICAgPT09PT09PQoKICAgIFN1YmhlYWRpbmcKICAgIC0tLS0tLS0tLS0KCiAgICAqIGEgbGlzdAogICAgKiBvZiBpdGVtcwoKICAgIDEuIGEgbnVtYmVyZWQKICAgIDIuIGxpc3QKCiAgICA+IGEgcXVvdGUKCkNvZGUgYmxvY2tzIGFyZSBpbmRlbnRlZCBieSBmb3VyIHNwYWNlcywgb3IgZmVuY2VkIGJ5IHRocmVlIGJhY2t0aWNrcy4KRmVuY2VkIGNvZGUgYmxvY2tzIGFyZSBoaWdobGlnaHRlZCB3aGVuIHRoZXkgbmFtZSB0aGVpciBsYW5ndWFnZSwgYW5kIGNhbgpudW1iZXIgdGhlaXIgbGluZXMgYW5kIGhpZ2hsaWdodCBzb21lIG9mIHRoZW06CgogICAgYGBgeWFtbCBsaW5lbm9zIGhsPTIsNC01CiAgICAuLi4KICAgIGBgYAoKVGFzayBMaXN0cwotLS0tLS0tLS0tCgogICAgLSBbIF0gZGVwbG95IGNhbmFyeQogICAgLSBbeF0gdGFnIHJlbGVhc2UKClRhc2tzIGFyZSBzaG93biBhcyBjaGVja2JveGVzOyBjaGVja2luZyBvbmUgc2F2ZXMgdGhlIHBhZ2UuCgpUYWJsZXMKLS0tLS0tCgogICAgQmljeWNsZSB8IFdoZWVscwogICAgLS0tLS0tLSB8IC0tLS0tLQogICAgVW5pY3ljbGUgfCAxCiAgICBUYW5kZW0gfCAyCg==
 `,
-	"templates/view.html": `e3tkZWZpbmUgInZpZXcifX0Ke3t0ZW1wbGF0ZSAiaGVhZGVyIiAufX0KCiAgICA8ZGl2Pnt7LkJvZHl9fTwvZGl2PgogICAgCnt7dGVtcGxhdGUgImZvb3RlciJ9fQp7e2VuZH19Cg==
+	"seed/index.md": `V2VsY29tZQo9PT09PT09CgpUaGlzIGlzIHRoZSBmcm9udCBwYWdlIG9mIHlvdXIgbmV3IHdpa2kuIENsaWNrIF9FZGl0XyBhYm92ZSB0byBjaGFuZ2UgaXQuCgpQYWdlcyBhcmUgd3JpdHRlbiBpbiBNYXJrZG93bjsgc2VlIFtoZWxwXSgpIGZvciBhIHF1aWNrIHJlZmVyZW5jZS4gVG8gY3JlYXRlIGEKbmV3IHBhZ2UsIGxpbmsgdG8gaXQgbGlrZSBgW3NvbWUvbmV3IHBhZ2VdKClgLCBmb2xsb3cgdGhlIGxpbmsgYW5kIHN0YXJ0CndyaXRpbmcuCg==
 `,
-	"goiki.toml": `IwojIEdvaWtpIENvbmZpZ3VyYXRpb24KIwoKIyBUaGUgbmFtZSBvZiB0aGUgd2lraTsgdGhpcyBpcyB1c2VkIGluIHRoZSBwYWNrYWdlZCB0ZW1wbGF0ZXMgcHJvdmlkZWQgYnkgR29pa2kKbmFtZSA9ICJHb2lraSIKCiMgSG9zdG5hbWUgb3IgSVAgYWRkcmVzcyB0aGUgd2Vic2VydmVyIHdpbGwgbGlzdGVuIG9uCmhvc3QgPSAiMC4wLjAuMCIKCiMgUG9ydCBudW1iZXIgdGhlIHdlYnNlcnZlciB3aWxsIGJpbmQgdG8KcG9ydCA9IDQ1NjcKCiMgUGF0aCB0byBkYXRhIGZpbGVzICh0aGUgR2l0IHJlcG8pCmRhdGFfZGlyID0gIi4vZGF0YSIKCiMgTmFtZSBvZiBwYWdlIHRvIHVzZSBmb3IgdGhlIGluZGV4IG9mIGEgY2F0ZWdvcnkgKG9yIGRpcmVjdG9yeSkKaW5kZXhfcGFnZSA9ICJob21lIgoKIyBGaWxlIGV4dGVuc2lvbiB0byB1c2Ugd2l0aGluIHRoZSBmaWxlc3lzdGVtCmZpbGVfZXh0ZW5zaW9uID0gIm1kIgoKIyBUaGVtZSB0byB1c2Ugd2l0aCBkZWZhdWx0IHRlbXBsYXRlczsgc2VlIGh0dHA6Ly9ib290c3dhdGNoLmNvbSBmb3IgZGV0YWlscy4KIyBWYWxpZCB2YWx1ZXMgYXJlOiAiZGVmYXVsdCIsICJjZXJ1bGVhbiIsICJjb3NtbyIsICJjeWJvcmciLCAiZGFya2x5IiwgImZsYXRseSIsCiMgImpvdXJuYWwiLCAibHVtZW4iLCAicGFwZXIiLCAicmVhZGFibGUiLCAic2FuZHN0b25lIiwgInNpbXBsZXgiLCAic2xhdGUiLAojICJzcGFjZWxhYiIsICJzdXBlcmhlcm8iLCAidW5pdGVkIiBhbmQgInlldGkiCnRoZW1lID0gImRlZmF1bHQiIAoKIyBQYXRoIHRvIGN1c3RvbSB0ZW1wbGF0ZXM7IGxlYXZlIGVtcHR5IHRvIHVzZSB0aGUgcGFja2FnZWQgdGVtcGxhdGVzCnRlbXBsYXRlX2RpciA9ICIiCgojIFBhdGggdG8gc3RhdGljIGNvbnRlbnQ7IGxlYXZlIGVtcHR5IHRvIHVzZSB0aGUgcGFja2FnZWQgY29udGVudApzdGF0aWNfZGlyID0gIiIKCiMgQ1NTIGNsYXNzKGVzKSB0byB1c2UgZm9yIHRhYmxlcwp0YWJsZV9jbGFzcyA9ICJ0YWJsZSB0YWJsZS1zdHJpcGVkIHRhYmxlLWhvdmVyIgoKIyBXaWtpIHVzZXJzLgojCiMgRWFjaCB1c2VyIGVudHJ5IG11c3QgcHJvdmlkZSBhIGBuYW1lYCwgYGVtYWlsYCwgYHVzZXJuYW1lYCBhbmQgYHBhc3N3b3JkYC4KIyBgbmFtZWAgYW5kIGBlbWFpbGAgYXJlIHVzZWQgZm9yIEdpdCBjb21taXRzLCB3aGlsZSBgdXNlcm5hbWVgIGFuZAojIGBwYXNzd29yZGAgYXJlIHVzZWQgZm9yIGF1dGhlbnRpY2F0aW5nIG92ZXIgSFRUUC4KIwojIFBhc3N3b3JkcyBjYW4gYmUgZ2VuZXJhdGVkIHVzaW5nIGBodHBhc3N3ZGAuIEJvdGggTUQ1IGFuZCBTSEExIHBhc3N3b3JkcwojIGFyZSBzdXBwb3J0ZWQuIAojCiMgUmVwZWF0IHRoZSBbW3VzZXJzXV0gc2VjdGlvbiBmb3IgYWRkaXRpb25hbCB1c2Vycy4KW1t1c2Vyc11dCm5hbWUgPSAiR29pa2kiCmVtYWlsID0gImdvaWtpQGV4YW1wbGUuY29tIgp1c2VybmFtZSA9ICJnb2lraSIKcGFzc3dvcmQgPSAie1NIQX00djArbUx0dmxYM3F5eTVJU3JRVTVtdzBZaGc9Igo=
+	"goiki.toml": `IwojIEdvaWtpIENvbmZpZ3VyYXRpb24KIwoKIyBUaGUgbmFtZSBvZiB0aGUgd2lraTsgdGhpcyBpcyB1c2VkIGluIHRoZSBwYWNrYWdlZCB0ZW1wbGF0ZXMgcHJvdmlkZWQgYnkgR29pa2kKbmFtZSA9ICJHb2lraSIKCiMgSG9zdG5hbWUgb3IgSVAgYWRkcmVzcyB0aGUgd2Vic2VydmVyIHdpbGwgbGlzdGVuIG9uCmhvc3QgPSAiMC4wLjAuMCIKCiMgUG9ydCBudW1iZXIgdGhlIHdlYnNlcnZlciB3aWxsIGJpbmQgdG8KcG9ydCA9IDQ1NjcKCiMgUGF0aCB0byBkYXRhIGZpbGVzICh0aGUgR2l0IHJlcG8pCmRhdGFfZGlyID0gIi4vZGF0YSIKCiMgU3RvcmFnZSBiYWNrZW5kIGZvciB0aGUgZGF0YSBmaWxlczogImdvLWdpdCIgdG8gdXNlIHRoZSBHaXQgaW1wbGVtZW50YXRpb24KIyBidWlsdCBpbnRvIEdvaWtpLCAiZ2l0IiB0byBydW4gdGhlIGdpdCBleGVjdXRhYmxlLCBvciAiZmlsZXMiIHRvIGtlZXAgcGxhaW4KIyBmaWxlcyB3aXRob3V0IEdpdCBhbmQgd2l0aG91dCBwYWdlIGhpc3RvcnkKc3RvcmFnZSA9ICJnby1naXQiCgojIEtlZXAgYSB0aW1lc3RhbXBlZCBiYWNrdXAgY29weSBvZiBldmVyeSBwYWdlIHNhdmVkLCBhcyBhIGxpZ2h0d2VpZ2h0IHBhZ2UKIyBoaXN0b3J5OyBvbmx5IHVzZWQgYnkgdGhlICJmaWxlcyIgc3RvcmFnZSBiYWNrZW5kCmJhY2t1cHMgPSB0cnVlCgojIE5hbWUgb2YgcGFnZSB0byB1c2UgZm9yIHRoZSBpbmRleCBvZiBhIGNhdGVnb3J5IChvciBkaXJlY3RvcnkpCmluZGV4X3BhZ2UgPSAiaG9tZSIKCiMgRmlsZSBleHRlbnNpb24gdG8gdXNlIHdpdGhpbiB0aGUgZmlsZXN5c3RlbQpmaWxlX2V4dGVuc2lvbiA9ICJtZCIKCiMgVGhlbWUgdG8gdXNlIHdpdGggZGVmYXVsdCB0ZW1wbGF0ZXM7IHNlZSBodHRwOi8vYm9vdHN3YXRjaC5jb20gZm9yIGRldGFpbHMuCiMgVmFsaWQgdmFsdWVzIGFyZTogImRlZmF1bHQiLCAiY2VydWxlYW4iLCAiY29zbW8iLCAiY3lib3JnIiwgImRhcmtseSIsICJmbGF0bHkiLAojICJqb3VybmFsIiwgImx1bWVuIiwgInBhcGVyIiwgInJlYWRhYmxlIiwgInNhbmRzdG9uZSIsICJzaW1wbGV4IiwgInNsYXRlIiwKIyAic3BhY2VsYWIiLCAic3VwZXJoZXJvIiwgInVuaXRlZCIgYW5kICJ5ZXRpIgp0aGVtZSA9ICJkZWZhdWx0IiAKCiMgUGF0aCB0byBjdXN0b20gdGVtcGxhdGVzOyBsZWF2ZSBlbXB0eSB0byB1c2UgdGhlIHBhY2thZ2VkIHRlbXBsYXRlcwp0ZW1wbGF0ZV9kaXIgPSAiIgoKIyBQYXRoIHRvIHN0YXRpYyBjb250ZW50OyBsZWF2ZSBlbXB0eSB0byB1c2UgdGhlIHBhY2thZ2VkIGNvbnRlbnQKc3RhdGljX2RpciA9ICIiCgojIENTUyBjbGFzcyhlcykgdG8gdXNlIGZvciB0YWJsZXMKdGFibGVfY2xhc3MgPSAidGFibGUgdGFibGUtc3RyaXBlZCB0YWJsZS1ob3ZlciIKCiMgUGF0aCB0byBHb2lraSdzIG93biBzdGF0ZSwgc3VjaCBhcyB0d28tZmFjdG9yIGVucm9sbG1lbnRzIGFuZCBjaGFuZ2UKIyByZXF1ZXN0czsgdGhpcyBpcyBrZXB0IG91dHNpZGUgb2YgdGhlIEdpdCByZXBvCnN0YXRlX2RpciA9ICIuL3N0YXRlIgoKIyBXYXRjaCB0aGUgR2l0IHJlcG8gZm9yIGNvbW1pdHMgbWFkZSBvdXRzaWRlIG9mIEdvaWtpLCBlLmcuIHB1c2hlZCBpbnRvIHRoZQojIGRhdGEgZGlyZWN0b3J5IG9yIGNvbW1pdHRlZCBvbiB0aGUgc2VydmVyLCBzbyB0aGF0IG5vdGhpbmcgZGVyaXZlZCBmcm9tIHRoZQojIGNvbnRlbnQgZ29lcyBzdGFsZS4gQWx0ZXJuYXRpdmVseSwgaGF2ZSBhIGBwb3N0LXJlY2VpdmVgIGhvb2sgUE9TVCB0bwojIGAvaG9va3MvcG9zdC1yZWNlaXZlYC4Kd2F0Y2hfcmVwbyA9IHRydWUKCiMgQXV0aGVudGljYXRpb24gYnkgYSByZXZlcnNlIHByb3h5LgojCiMgV2hlbiBlbmFibGVkLCByZXF1ZXN0cyBjb21pbmcgZnJvbSBvbmUgb2YgdGhlIGB0cnVzdGVkX3Byb3hpZXNgIG5ldHdvcmtzCiMgKGluIENJRFIgbm90YXRpb24pIGFyZSBhdXRoZW50aWNhdGVkIGJ5IHRoZSB1c2VybmFtZSwgbmFtZSBhbmQgZW1haWwgdGhlCiMgcHJveHkgcGFzc2VzIGFsb25nIGluIHRoZSBjb25maWd1cmVkIGhlYWRlcnMuIFRoZSBuYW1lIGFuZCBlbWFpbCBhcmUgdXNlZAojIGZvciBHaXQgY29tbWl0cy4gQWxsIG90aGVyIHJlcXVlc3RzIGZhbGwgYmFjayB0byBIVFRQIEJhc2ljIGF1dGhlbnRpY2F0aW9uCiMgYWdhaW5zdCB0aGUgd2lraSB1c2VycyBiZWxvdy4KW3Byb3h5X2F1dGhdCmVuYWJsZWQgPSBmYWxzZQp1c2VyX2hlYWRlciA9ICJYLVJlbW90ZS1Vc2VyIgpuYW1lX2hlYWRlciA9ICJYLVJlbW90ZS1OYW1lIgplbWFpbF9oZWFkZXIgPSAiWC1SZW1vdGUtRW1haWwiCnRydXN0ZWRfcHJveGllcyA9IFsiMTI3LjAuMC4xLzMyIiwgIjo6MS8xMjgiXQoKIyBUaHJvdHRsaW5nIG9mIGZhaWxlZCBsb2dpbnMuCiMKIyBGYWlsZWQgSFRUUCBCYXNpYyBsb2dpbnMgYXJlIGNvdW50ZWQgcGVyIHJlbW90ZSBhZGRyZXNzIGFuZCBwZXIgdXNlcm5hbWUuCiMgQWZ0ZXIgZWFjaCBmYWlsdXJlIGZ1cnRoZXIgYXR0ZW1wdHMgYXJlIHJlZnVzZWQgZm9yIGBiYWNrb2ZmYCwgZG91Ymxpbmcgd2l0aAojIGV2ZXJ5IGNvbnNlY3V0aXZlIGZhaWx1cmUgdXAgdG8gYG1heF9iYWNrb2ZmYC4gQWZ0ZXIgYG1heF9mYWlsdXJlc2AKIyBjb25zZWN1dGl2ZSBmYWlsdXJlcyB0aGUgYWRkcmVzcyBvciB1c2VybmFtZSBpcyBsb2NrZWQgb3V0IGZvciBgbG9ja291dGAuCiMgQmxvY2tlZCBhdHRlbXB0cyBhcmUgbG9nZ2VkLgpbbG9naW5fdGhyb3R0bGVdCmVuYWJsZWQgPSB0cnVlCmJhY2tvZmYgPSAiMXMiCm1heF9iYWNrb2ZmID0gIjFtIgptYXhfZmFpbHVyZXMgPSAxMApsb2Nrb3V0ID0gIjE1bSIKCiMgVHdvLWZhY3RvciBhdXRoZW50aWNhdGlvbi4KIwojIFdoZW4gZW5hYmxlZCwgdXNlcnMgY2FuIGVucm9sbCBmb3IgdGltZS1iYXNlZCBvbmUtdGltZSBwYXNzd29yZHMgKFRPVFApIGF0CiMgYC8yZmEvZW5yb2xsYCB3aXRoIGFueSBhdXRoZW50aWNhdG9yIGFwcC4gRW5yb2xsZWQgdXNlcnMgaGF2ZSB0byBlbnRlciBhCiMgY29kZSwgb3Igb25lIG9mIHRoZWlyIHJlY292ZXJ5IGNvZGVzLCBiZWZvcmUgdGhleSBjYW4gZWRpdCBwYWdlcy4gVGhlCiMgc2Vjb25kIGZhY3RvciBpcyByZW1lbWJlcmVkIGZvciBgc2Vzc2lvbmA7IHNlc3Npb25zIGRvIG5vdCBzdXJ2aXZlIGEKIyByZXN0YXJ0LiBTZXQgYHJlcXVpcmVkYCB0byBtYWtlIGVucm9sbG1lbnQgbWFuZGF0b3J5IGZvciBhbGwgZWRpdG9ycy4KIyBgaXNzdWVyYCBpcyBzaG93biBpbiBhdXRoZW50aWNhdG9yIGFwcHMgYW5kIGRlZmF1bHRzIHRvIHRoZSB3aWtpIG5hbWUuClt0d29fZmFjdG9yXQplbmFibGVkID0gdHJ1ZQpyZXF1aXJlZCA9IGZhbHNlCmlzc3VlciA9ICIiCnNlc3Npb24gPSAiMTJoIgoKIyBTeW5jaHJvbml6YXRpb24gd2l0aCBhIEdpdCByZW1vdGUuCiMKIyBXaGVuIGEgYHJlbW90ZWAgKGEgcmVtb3RlIG5hbWUgb3IgVVJMKSBpcyBzZXQsIEdvaWtpIHB1bGxzIGBicmFuY2hgIGZyb20gaXQKIyBvbiBzdGFydHVwLCBldmVyeSBgaW50ZXJ2YWxgIGFuZCBiZWZvcmUgZWFjaCBzYXZlLCBhbmQgcHVzaGVzIGFmdGVyIGVhY2gKIyBjb21taXQuIGBzdHJhdGVneWAgaXMgZWl0aGVyICJyZWJhc2UiIG9yICJtZXJnZSIuIFB1bGxzIHJ1bm5pbmcgaW50bwojIGNvbmZsaWN0cyBhcmUgYWJvcnRlZDsgdGhlIHN5bmMgc3RhdHVzIGFuZCBjb25mbGljdHMgYXJlIHNob3duIHRvIGFkbWlucyBhdAojIGAvYWRtaW4vc3luY2AuCltzeW5jXQpyZW1vdGUgPSAiIgpicmFuY2ggPSAibWFzdGVyIgpzdHJhdGVneSA9ICJyZWJhc2UiCmludGVydmFsID0gIjVtIgoKIyBDYWNoZSBvZiByZW5kZXJlZCBwYWdlcy4KIwojIFJlbmRlcmVkIHBhZ2VzIGFyZSBrZXB0IGluIG1lbW9yeSwga2V5ZWQgYnkgdGhlIEdpdCBibG9iIG9mIHRoZSBwYWdlIGFuZCB0aGUKIyByZW5kZXIgc2V0dGluZ3MsIHNvIGEgY2FjaGVkIHBhZ2UgbmV2ZXIgZ29lcyBzdGFsZS4gVGhlIGxlYXN0IHJlY2VudGx5CiMgdmlld2VkIHBhZ2VzIGFyZSBldmljdGVkIGJleW9uZCBgbWF4X2VudHJpZXNgIHBhZ2VzIG9yIGBtYXhfYnl0ZXNgIGJ5dGVzIG9mCiMgSFRNTDsgMCBtZWFucyBubyBsaW1pdC4gU2V0IGJvdGggdG8gMCB0byBkaXNhYmxlIHRoZSBjYWNoZS4gQWRtaW5zIGNhbiBzZWUKIyB0aGUgaGl0IGFuZCBtaXNzIHN0YXRpc3RpY3MgYXQgYC9hZG1pbi9jYWNoZWAuCltjYWNoZV0KbWF4X2VudHJpZXMgPSAxMDAwCm1heF9ieXRlcyA9IDE2Nzc3MjE2CgojIEVkaXQgbG9ja3MuCiMKIyBPcGVuaW5nIGEgcGFnZSBpbiB0aGUgZWRpdG9yIHRha2VzIGFuIGFkdmlzb3J5IGxvY2sgb24gaXQsIHdoaWNoIHRoZSBlZGl0b3IKIyBrZWVwcyBhbGl2ZSB3aXRoIGEgaGVhcnRiZWF0LiBPdGhlcnMgb3BlbmluZyB0aGUgcGFnZSBhcmUgdG9sZCB3aG8gaXMKIyBlZGl0aW5nIGl0IHNpbmNlIHdoZW4sIGFuZCBjYW4gdGFrZSBvdmVyIHRoZSBsb2NrLiBBIGxvY2sgZXhwaXJlcyBgZXhwaXJlYAojIGFmdGVyIHRoZSBsYXN0IGhlYXJ0YmVhdCwgZS5nLiB3aGVuIHRoZSBlZGl0b3Igd2FzIGNsb3NlZC4KW2VkaXRfbG9ja10KZW5hYmxlZCA9IHRydWUKZXhwaXJlID0gIjJtIgoKIyBBdXRvc2F2ZS4KIwojIFRoZSBlZGl0b3Igc2F2ZXMgdGhlIGVkaXQgaW4gcHJvZ3Jlc3MgZXZlcnkgYGludGVydmFsYCB0byB0aGUgc3RhdGUKIyBkaXJlY3RvcnksIG91dHNpZGUgb2YgdGhlIHBhZ2UgaGlzdG9yeS4gSWYgdGhlIGVkaXQgaXMgbG9zdCwgZS5nLiB3aGVuIHRoZQojIGJyb3dzZXIgY3Jhc2hlZCwgaXQgaXMgb2ZmZXJlZCBiYWNrIHdoZW4gdGhlIHBhZ2UgaXMgb3BlbmVkIGluIHRoZSBlZGl0b3IKIyBhZ2Fpbi4gSXQgaXMgZHJvcHBlZCB3aGVuIHRoZSBwYWdlIGlzIHNhdmVkLgpbYXV0b3NhdmVdCmVuYWJsZWQgPSB0cnVlCmludGVydmFsID0gIjE1cyIKCiMgU3ludGF4IGhpZ2hsaWdodGluZy4KIwojIEZlbmNlZCBjb2RlIGJsb2NrcyB3aXRoIGEgbGFuZ3VhZ2UsIGUuZy4gYGBgZ28sIGFyZSBoaWdobGlnaHRlZCB3aXRoIHRoZQojIENocm9tYSBgc3R5bGVgLCBzdWNoIGFzICJnaXRodWIiLCAibW9ub2thaSIgb3IgInNvbGFyaXplZC1kYXJrIiAoc2VlCiMgaHR0cHM6Ly94eXByb3RvLmdpdGh1Yi5pby9zcGxhc2gvZG9jcy8pOyBsZWF2ZSBpdCBlbXB0eSB0byBtYXRjaCB0aGUgdGhlbWUuCiMgV2l0aCBgbGluZV9udW1iZXJzYCwgY29kZSBibG9ja3MgYXJlIG51bWJlcmVkIHVubGVzcyB0aGV5IHNheSBvdGhlcndpc2UsIGFzCiMgaW4gYGBgZ28gbm9saW5lbm9zLiBMaW5lcyBhcmUgaGlnaGxpZ2h0ZWQgYXMgaW4gYGBgZ28gaGw9Miw1LTcuCltoaWdobGlnaHRdCnN0eWxlID0gIiIKbGluZV9udW1iZXJzID0gZmFsc2UKCiMgV2lraSB1c2Vycy4KIwojIEVhY2ggdXNlciBlbnRyeSBtdXN0IHByb3ZpZGUgYSBgbmFtZWAsIGBlbWFpbGAsIGB1c2VybmFtZWAgYW5kIGBwYXNzd29yZGAuCiMgYG5hbWVgIGFuZCBgZW1haWxgIGFyZSB1c2VkIGZvciBHaXQgY29tbWl0cywgd2hpbGUgYHVzZXJuYW1lYCBhbmQKIyBgcGFzc3dvcmRgIGFyZSB1c2VkIGZvciBhdXRoZW50aWNhdGluZyBvdmVyIEhUVFAuIFVzZXJzIHdpdGggYGFkbWluYCBzZXQKIyB0byB0cnVlIGhhdmUgYWNjZXNzIHRvIHRoZSBhZG1pbiBwYWdlcy4gVXNlcnMgd2l0aCBgYXBwcm92ZXJgIHNldCB0byB0cnVlLAojIGFuZCBhZG1pbnMsIGNhbiBtZXJnZSBjaGFuZ2UgcmVxdWVzdHMuCiMKIyBQYXNzd29yZHMgY2FuIGJlIGdlbmVyYXRlZCB1c2luZyBgaHRwYXNzd2RgLiBCb3RoIE1ENSBhbmQgU0hBMSBwYXNzd29yZHMKIyBhcmUgc3VwcG9ydGVkLiAKIwojIFJlcGVhdCB0aGUgW1t1c2Vyc11dIHNlY3Rpb24gZm9yIGFkZGl0aW9uYWwgdXNlcnMuCltbdXNlcnNdXQpuYW1lID0gIkdvaWtpIgplbWFpbCA9ICJnb2lraUBleGFtcGxlLmNvbSIKdXNlcm5hbWUgPSAiZ29pa2kiCnBhc3N3b3JkID0gIntTSEF9NHYwK21MdHZsWDNxeXk1SVNyUVU1bXcwWWhnPSIKYWRtaW4gPSB0cnVlCmFwcHJvdmVyID0gdHJ1ZQo=
 `,
 }
 
@@ -22,6 +22,7 @@ function bundleFile {
 echo "var _bundle = map[string]string{" >> $dest
 
 bundleDir templates
+bundleDir seed
 bundleFile goiki.toml
 
 echo -e "}\n" >> $dest
\ No newline at end of file
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"container/list"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+
+	auth "github.com/abbot/go-http-auth"
+)
+
+var rendered *renderCache
+
+// renderCache is an LRU cache of rendered pages. Entries are keyed by the
+// hash of the page's Git blob, the render settings and the set of pages, so
+// a new commit or a change in settings never hits a stale entry.
+type renderCache struct {
+	sync.Mutex
+	maxEntries int
+	maxBytes   int
+	bytes      int
+	entries    map[string]*list.Element
+	order      *list.List
+	hits       uint64
+	misses     uint64
+	evictions  uint64
+}
+
+type cacheEntry struct {
+	key  string
+	html string
+}
+
+type cacheStats struct {
+	Entries    int    `json:"entries"`
+	MaxEntries int    `json:"max_entries"`
+	Bytes      int    `json:"bytes"`
+	MaxBytes   int    `json:"max_bytes"`
+	Hits       uint64 `json:"hits"`
+	Misses     uint64 `json:"misses"`
+	Evictions  uint64 `json:"evictions"`
+}
+
+// newRenderCache returns a cache holding at most maxEntries pages of at most
+// maxBytes in total; a limit of 0 means no limit on that dimension.
+func newRenderCache(maxEntries int, maxBytes int) *renderCache {
+	return &renderCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func (c *renderCache) get(key string) (string, bool) {
+	c.Lock()
+	defer c.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return "", false
+	}
+	c.hits++
+	c.order.MoveToFront(e)
+	return e.Value.(*cacheEntry).html, true
+}
+
+func (c *renderCache) put(key string, html string) {
+	c.Lock()
+	defer c.Unlock()
+	if c.maxBytes > 0 && len(html) > c.maxBytes {
+		return
+	}
+	if e, ok := c.entries[key]; ok {
+		c.bytes += len(html) - len(e.Value.(*cacheEntry).html)
+		e.Value.(*cacheEntry).html = html
+		c.order.MoveToFront(e)
+	} else {
+		c.entries[key] = c.order.PushFront(&cacheEntry{key: key, html: html})
+		c.bytes += len(html)
+	}
+	for (c.maxEntries > 0 && c.order.Len() > c.maxEntries) || (c.maxBytes > 0 && c.bytes > c.maxBytes) {
+		c.evict()
+	}
+}
+
+func (c *renderCache) evict() {
+	e := c.order.Back()
+	entry := e.Value.(*cacheEntry)
+	c.order.Remove(e)
+	delete(c.entries, entry.key)
+	c.bytes -= len(entry.html)
+	c.evictions++
+}
+
+func (c *renderCache) stats() cacheStats {
+	c.Lock()
+	defer c.Unlock()
+	return cacheStats{
+		Entries:    c.order.Len(),
+		MaxEntries: c.maxEntries,
+		Bytes:      c.bytes,
+		MaxBytes:   c.maxBytes,
+		Hits:       c.hits,
+		Misses:     c.misses,
+		Evictions:  c.evictions,
+	}
+}
+
+// renderKey returns the cache key of the blob of page. It covers the page,
+// against which relative links are resolved, every setting that changes the
+// rendered output, and the set of pages, which decides the links marked as
+// missing.
+func renderKey(page string, blob string) string {
+	return page + "|" + blob + "|" + conf.TableClass + "|" + highlightStyle() + "|" + strconv.FormatBool(conf.Highlight.LineNumbers) + "|" + wikiPages.key()
+}
+
+func cacheHandler(w http.ResponseWriter, r *auth.AuthenticatedRequest) {
+	if !conf.Auth[r.Username].Admin {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if rendered == nil {
+		http.NotFound(w, &r.Request)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rendered.stats())
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestRenderCacheEntries(t *testing.T) {
+	c := newRenderCache(2, 0)
+	c.put("a", "<p>a</p>")
+	c.put("b", "<p>b</p>")
+	c.get("a")
+	c.put("c", "<p>c</p>")
+
+	if _, ok := c.get("b"); ok {
+		t.Errorf("Least recently used entry should have been evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if html, ok := c.get(key); !ok || html != "<p>"+key+"</p>" {
+			t.Errorf("Entry %s should be cached, but got >%s<", key, html)
+		}
+	}
+
+	stats := c.stats()
+	expected := cacheStats{Entries: 2, MaxEntries: 2, Bytes: 16, Hits: 3, Misses: 1, Evictions: 1}
+	if stats != expected {
+		t.Errorf("Stats should equal >%+v<, but are >%+v<", expected, stats)
+	}
+}
+
+func TestRenderCacheBytes(t *testing.T) {
+	c := newRenderCache(0, 20)
+	c.put("a", "0123456789")
+	c.put("b", "0123456789")
+	c.put("c", "0123456789")
+	c.put("huge", "012345678901234567890")
+
+	if _, ok := c.get("a"); ok {
+		t.Errorf("Entries beyond the byte limit should have been evicted")
+	}
+	if _, ok := c.get("huge"); ok {
+		t.Errorf("Entries larger than the byte limit should not be cached")
+	}
+	if stats := c.stats(); stats.Bytes != 20 || stats.Entries != 2 {
+		t.Errorf("Cache should hold 2 entries of 20 bytes, but holds %d of %d bytes", stats.Entries, stats.Bytes)
+	}
+}
+
+func TestRenderKey(t *testing.T) {
+	dir := initRepo()
+	defer discardRepo(dir)
+	conf.FileExtension = "md"
+	defer func() { conf = config{} }()
+	defer wikiPages.reset()
+
+	if renderKey("team-a/index", "cafe") == renderKey("team-b/index", "cafe") {
+		t.Errorf("Pages with the same blob in different directories should not share a key")
+	}
+}
@@ -3,13 +3,80 @@ package main
 import (
 	"github.com/BurntSushi/toml"
 	"io/ioutil"
+	"net"
+	"time"
 )
 
+// duration is a time.Duration that can be decoded from a TOML string such
+// as "1m30s".
+type duration struct {
+	time.Duration
+}
+
+func (d *duration) UnmarshalText(text []byte) error {
+	var err error
+	d.Duration, err = time.ParseDuration(string(text))
+	return err
+}
+
 type user struct {
 	Name     string
 	Email    string
 	Username string
 	Password string
+	Admin    bool
+	Approver bool
+}
+
+type proxyAuth struct {
+	Enabled        bool
+	UserHeader     string   `toml:"user_header"`
+	NameHeader     string   `toml:"name_header"`
+	EmailHeader    string   `toml:"email_header"`
+	TrustedProxies []string `toml:"trusted_proxies"`
+	networks       []*net.IPNet
+}
+
+type loginThrottle struct {
+	Enabled     bool
+	Backoff     duration
+	MaxBackoff  duration `toml:"max_backoff"`
+	MaxFailures int      `toml:"max_failures"`
+	Lockout     duration
+}
+
+type twoFactor struct {
+	Enabled  bool
+	Required bool
+	Issuer   string
+	Session  duration
+}
+
+type syncConfig struct {
+	Remote   string
+	Branch   string
+	Strategy string
+	Interval duration
+}
+
+type cacheConfig struct {
+	MaxEntries int `toml:"max_entries"`
+	MaxBytes   int `toml:"max_bytes"`
+}
+
+type editLockConfig struct {
+	Enabled bool
+	Expire  duration
+}
+
+type autosaveConfig struct {
+	Enabled  bool
+	Interval duration
+}
+
+type highlightConfig struct {
+	Style       string
+	LineNumbers bool `toml:"line_numbers"`
 }
 
 type config struct {
@@ -23,6 +90,18 @@ type config struct {
 	TemplateDir   string `toml:"template_dir"`
 	StaticDir     string `toml:"static_dir"`
 	TableClass    string `toml:"table_class"`
+	Storage       string
+	Backups       bool
+	StateDir      string        `toml:"state_dir"`
+	WatchRepo     bool          `toml:"watch_repo"`
+	ProxyAuth     proxyAuth     `toml:"proxy_auth"`
+	LoginThrottle loginThrottle `toml:"login_throttle"`
+	TwoFactor     twoFactor     `toml:"two_factor"`
+	Sync          syncConfig
+	Cache         cacheConfig
+	EditLock      editLockConfig `toml:"edit_lock"`
+	Autosave      autosaveConfig
+	Highlight     highlightConfig
 	Users         []user
 	Auth          map[string]user
 }
@@ -35,6 +114,19 @@ func (c *config) loadAuth() {
 	c.Auth = auth
 }
 
+func (c *config) loadProxies() error {
+	networks := make([]*net.IPNet, 0, len(c.ProxyAuth.TrustedProxies))
+	for _, cidr := range c.ProxyAuth.TrustedProxies {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return err
+		}
+		networks = append(networks, network)
+	}
+	c.ProxyAuth.networks = networks
+	return nil
+}
+
 func loadConfigFromFile(file string) (config, error) {
 	var c config
 	data, err := ioutil.ReadFile(file)
@@ -3,6 +3,7 @@ package main
 import (
 	"io/ioutil"
 	"testing"
+	"time"
 )
 
 var testConfigFile string = "./goiki.toml"
@@ -121,3 +122,34 @@ func TestConfigUsers(t *testing.T) {
 		t.Errorf("User Password should euqual >%s<, but is >%s<", u.Password, c.Users[0].Password)
 	}
 }
+
+func TestConfigProxyAuth(t *testing.T) {
+	c, _ := loadConfigFromFile(testConfigFile)
+	if c.ProxyAuth.Enabled {
+		t.Errorf("ProxyAuth should be disabled by default")
+	}
+
+	userHeader := "X-Remote-User"
+	if c.ProxyAuth.UserHeader != userHeader {
+		t.Errorf("ProxyAuth UserHeader should equal >%s<, but is >%s<", userHeader, c.ProxyAuth.UserHeader)
+	}
+
+	if err := c.loadProxies(); err != nil {
+		t.Errorf("Default trusted proxies should parse, but got error: %v", err)
+	}
+	if len(c.ProxyAuth.networks) != len(c.ProxyAuth.TrustedProxies) {
+		t.Errorf("Number of trusted networks should equal >%d<, but is >%d<", len(c.ProxyAuth.TrustedProxies), len(c.ProxyAuth.networks))
+	}
+}
+
+func TestConfigLoginThrottle(t *testing.T) {
+	c, _ := loadConfigFromFile(testConfigFile)
+	if !c.LoginThrottle.Enabled {
+		t.Errorf("LoginThrottle should be enabled by default")
+	}
+
+	lockout := 15 * time.Minute
+	if c.LoginThrottle.Lockout.Duration != lockout {
+		t.Errorf("LoginThrottle Lockout should equal >%v<, but is >%v<", lockout, c.LoginThrottle.Lockout.Duration)
+	}
+}
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	auth "github.com/abbot/go-http-auth"
+)
+
+const (
+	draftCookie = "goiki_draft"
+
+	changeOpen   = "open"
+	changeMerged = "merged"
+	changeClosed = "closed"
+)
+
+var (
+	changes    *changeStore
+	validDraft = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+	// publicRevision matches the revisions anyone may read: the live wiki and
+	// commits by hash, as linked from the history. Names of branches, which
+	// may resolve to drafts however they are spelled, are not public.
+	publicRevision = regexp.MustCompile(`^(HEAD|[0-9a-f]{4,40})?$`)
+
+	errNoDrafts = errors.New("The storage backend does not support drafts")
+)
+
+// changeRequest asks for the edits on a draft branch to be merged into the
+// wiki.
+type changeRequest struct {
+	ID       int
+	Draft    string
+	Summary  string
+	Username string
+	Author   author
+	Opened   time.Time
+	State    string
+	ClosedBy string
+	Closed   time.Time
+}
+
+// changeStore keeps the change requests in a JSON file in the state dir.
+type changeStore struct {
+	sync.Mutex
+	file    string
+	changes []*changeRequest
+	now     func() time.Time
+}
+
+type changesPage struct {
+	SiteName string
+	Title    string
+	Theme    string
+	Changes  []changeRequest
+	Draft    string
+	Error    string
+}
+
+type changePage struct {
+	SiteName string
+	Title    string
+	Theme    string
+	Change   changeRequest
+	Diff     []diffLine
+	CanMerge bool
+	CanClose bool
+	Error    string
+}
+
+// diffLine is a line of a unified diff with the CSS class to show it with.
+type diffLine struct {
+	Class string
+	Text  string
+}
+
+func loadChangeStore(file string) (*changeStore, error) {
+	s := &changeStore{file: file, now: time.Now}
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return s, json.Unmarshal(data, &s.changes)
+}
+
+func (s *changeStore) save() error {
+	return saveJSON(s.file, s.changes)
+}
+
+// open opens a change request for draft, unless one is open already.
+func (s *changeStore) open(draft string, summary string, username string, author author) (changeRequest, error) {
+	s.Lock()
+	defer s.Unlock()
+	for _, c := range s.changes {
+		if c.Draft == draft && c.State == changeOpen {
+			return *c, fmt.Errorf("Change request #%d is already open for %s", c.ID, draft)
+		}
+	}
+	c := &changeRequest{
+		ID:       len(s.changes) + 1,
+		Draft:    draft,
+		Summary:  summary,
+		Username: username,
+		Author:   author,
+		Opened:   s.now(),
+		State:    changeOpen,
+	}
+	s.changes = append(s.changes, c)
+	return *c, s.save()
+}
+
+func (s *changeStore) get(id int) (changeRequest, bool) {
+	s.Lock()
+	defer s.Unlock()
+	if id < 1 || id > len(s.changes) {
+		return changeRequest{}, false
+	}
+	return *s.changes[id-1], true
+}
+
+// list returns all change requests, latest first.
+func (s *changeStore) list() []changeRequest {
+	s.Lock()
+	defer s.Unlock()
+	list := make([]changeRequest, len(s.changes))
+	for i, c := range s.changes {
+		list[len(s.changes)-1-i] = *c
+	}
+	return list
+}
+
+// close marks an open change request as merged or closed by username.
+func (s *changeStore) close(id int, state string, username string) error {
+	s.Lock()
+	defer s.Unlock()
+	if id < 1 || id > len(s.changes) {
+		return fmt.Errorf("Unknown change request #%d", id)
+	}
+	c := s.changes[id-1]
+	if c.State != changeOpen {
+		return fmt.Errorf("Change request #%d is %s already", id, c.State)
+	}
+	c.State = state
+	c.ClosedBy = username
+	c.Closed = s.now()
+	return s.save()
+}
+
+func draftBranch(draft string) string {
+	return "drafts/" + draft
+}
+
+func draftsEnabled() bool {
+	_, ok := store.(branchStorage)
+	return ok && changes != nil
+}
+
+func canApprove(username string) bool {
+	return conf.Auth[username].Admin || conf.Auth[username].Approver
+}
+
+// requestDraft returns the draft a request is about, if any, given as the
+// draft parameter or remembered in a cookie while previewing.
+func requestDraft(r *http.Request) string {
+	if !draftsEnabled() {
+		return ""
+	}
+	draft := r.FormValue("draft")
+	if _, ok := r.Form["draft"]; !ok {
+		if cookie, err := r.Cookie(draftCookie); err == nil {
+			draft = cookie.Value
+		}
+	}
+	if !validDraft.MatchString(draft) {
+		return ""
+	}
+	return draft
+}
+
+// previewDraft remembers the draft being previewed, or forgets it if the
+// draft parameter is empty, so that the whole wiki can be browsed as it
+// looks on the draft.
+func previewDraft(w http.ResponseWriter, r *http.Request) string {
+	draft := requestDraft(r)
+	if _, ok := r.URL.Query()["draft"]; ok {
+		cookie := &http.Cookie{Name: draftCookie, Value: draft, Path: "/", HttpOnly: true}
+		if len(draft) == 0 {
+			cookie.MaxAge = -1
+		}
+		http.SetCookie(w, cookie)
+	}
+	return draft
+}
+
+// readsDraft tells whether a request may read a draft, while previewing it or
+// by a revision other than a public one.
+func readsDraft(r *http.Request) bool {
+	return len(requestDraft(r)) > 0 || !publicRevision.MatchString(r.FormValue("revision"))
+}
+
+// draftWrap lets anyone read the live wiki, but only users read drafts, which
+// are not reviewed yet.
+func draftWrap(fn http.HandlerFunc) http.HandlerFunc {
+	private := authWrap(func(w http.ResponseWriter, r *auth.AuthenticatedRequest) {
+		fn(w, &r.Request)
+	})
+	return func(w http.ResponseWriter, r *http.Request) {
+		if readsDraft(r) {
+			private(w, r)
+			return
+		}
+		fn(w, r)
+	}
+}
+
+// diffLines splits a unified diff into lines and classifies them.
+func diffLines(diff string) []diffLine {
+	var lines []diffLine
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		class := ""
+		switch {
+		case strings.HasPrefix(line, "diff "), strings.HasPrefix(line, "index "),
+			strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			class = "active"
+		case strings.HasPrefix(line, "@@"):
+			class = "info"
+		case strings.HasPrefix(line, "+"):
+			class = "success"
+		case strings.HasPrefix(line, "-"):
+			class = "danger"
+		}
+		lines = append(lines, diffLine{Class: class, Text: line})
+	}
+	return lines
+}
+
+// mergeChange merges the draft of c into the wiki and deletes the draft
+// branch.
+func mergeChange(c changeRequest, author author) error {
+	drafts, ok := store.(branchStorage)
+	if !ok {
+		return errNoDrafts
+	}
+
+	repoLock.Lock()
+	defer repoLock.Unlock()
+	if syncEnabled() {
+		syncPull()
+	}
+
+	message := fmt.Sprintf("Merge draft %s: %s", c.Draft, c.Summary)
+	if err := drafts.Merge(draftBranch(c.Draft), message, author); err != nil {
+		return err
+	}
+	log.Printf("Merged draft %s\n", c.Draft)
+	if err := drafts.DeleteBranch(draftBranch(c.Draft)); err != nil {
+		log.Printf("Unable to delete draft branch %s: %v\n", draftBranch(c.Draft), err)
+	}
+
+	if syncEnabled() {
+		syncPush()
+	}
+	checkHead()
+	return nil
+}
+
+// changesHandler lists change requests at /changes/ and opens new ones when
+// posted to. /changes/<id> shows a change request with its diff, which
+// approvers merge by posting to /changes/<id>/merge. Its author and
+// approvers can close it by posting to /changes/<id>/close.
+func changesHandler(w http.ResponseWriter, r *auth.AuthenticatedRequest) {
+	drafts, ok := store.(branchStorage)
+	if !ok || changes == nil {
+		http.NotFound(w, &r.Request)
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/changes"), "/")
+	if len(path) == 0 {
+		p := &changesPage{Title: "Change requests", Theme: conf.Theme, SiteName: conf.Name}
+		if r.Method == "POST" {
+			draft := r.FormValue("draft")
+			summary := strings.TrimSpace(r.FormValue("summary"))
+			if len(summary) == 0 {
+				summary = draft
+			}
+			if !validDraft.MatchString(draft) {
+				p.Error = "Draft names may only contain letters, digits, dashes and underscores."
+			} else if diff, err := drafts.Diff(draftBranch(draft)); err != nil {
+				p.Error = fmt.Sprintf("There is no draft %s.", draft)
+			} else if len(diff) == 0 {
+				p.Error = fmt.Sprintf("The draft %s has no changes.", draft)
+			} else if c, err := changes.open(draft, summary, r.Username, requestAuthor(r)); err != nil {
+				p.Error = err.Error()
+			} else {
+				http.Redirect(w, &r.Request, fmt.Sprintf("/changes/%d", c.ID), http.StatusFound)
+				return
+			}
+		}
+		p.Draft = r.FormValue("draft")
+		p.Changes = changes.list()
+		renderTemplate(w, "changes", p)
+		return
+	}
+
+	parts := strings.SplitN(path, "/", 2)
+	id, err := strconv.Atoi(parts[0])
+	c, ok := changes.get(id)
+	if err != nil || !ok {
+		http.NotFound(w, &r.Request)
+		return
+	}
+	p := &changePage{
+		Title:    fmt.Sprintf("Change request #%d", c.ID),
+		Theme:    conf.Theme,
+		SiteName: conf.Name,
+		CanMerge: c.State == changeOpen && canApprove(r.Username),
+		CanClose: c.State == changeOpen && (canApprove(r.Username) || r.Username == c.Username),
+	}
+
+	if len(parts) == 2 {
+		if r.Method != "POST" {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		switch {
+		case parts[1] == "merge" && p.CanMerge:
+			err = mergeChange(c, requestAuthor(r))
+			if err == nil {
+				err = changes.close(c.ID, changeMerged, r.Username)
+			}
+		case parts[1] == "close" && p.CanClose:
+			err = changes.close(c.ID, changeClosed, r.Username)
+		case parts[1] == "merge" || parts[1] == "close":
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		default:
+			http.NotFound(w, &r.Request)
+			return
+		}
+		if err == nil {
+			http.Redirect(w, &r.Request, fmt.Sprintf("/changes/%d", c.ID), http.StatusFound)
+			return
+		}
+		if conflict, ok := err.(*mergeConflict); ok {
+			p.Error = fmt.Sprintf("The draft conflicts with changes made since in %s. Update the draft, then merge again.",
+				strings.Join(conflict.Files, ", "))
+		} else {
+			p.Error = err.Error()
+		}
+	}
+
+	p.Change = c
+	if c.State == changeOpen {
+		diff, err := drafts.Diff(draftBranch(c.Draft))
+		if err != nil && len(p.Error) == 0 {
+			p.Error = err.Error()
+		}
+		p.Diff = diffLines(diff)
+	}
+	renderTemplate(w, "change", p)
+}
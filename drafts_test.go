@@ -0,0 +1,110 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	auth "github.com/abbot/go-http-auth"
+)
+
+func TestChangeStore(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "goiki-changes")
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "changes.json")
+	s, err := loadChangeStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := author{Name: "Test", Email: "test@example.com"}
+	first, err := s.open("rewrite", "Rewrite bicycle", "test", a)
+	if first.ID != 1 || first.State != changeOpen || err != nil {
+		t.Errorf("First change request should be #1 and open, but is %+v (error: %v)", first, err)
+	}
+	if _, err = s.open("rewrite", "Again", "test", a); err == nil {
+		t.Errorf("Opening a second change request for the same draft should fail")
+	}
+	s.open("typos", "Fix typos", "test", a)
+
+	if err = s.close(1, changeMerged, "goiki"); err != nil {
+		t.Errorf("Unable to close change request #1: %v", err)
+	}
+	if err = s.close(1, changeClosed, "goiki"); err == nil {
+		t.Errorf("Closing a merged change request should fail")
+	}
+
+	s, _ = loadChangeStore(file)
+	list := s.list()
+	if len(list) != 2 || list[0].Draft != "typos" || list[1].State != changeMerged || list[1].ClosedBy != "goiki" {
+		t.Errorf("Change requests should be kept, latest first, but are %+v", list)
+	}
+	if c, ok := s.get(2); !ok || c.Summary != "Fix typos" {
+		t.Errorf("Change request #2 should be >Fix typos<, but is %+v", c)
+	}
+	if _, ok := s.get(3); ok {
+		t.Errorf("Change request #3 should not exist")
+	}
+	if c, _ := s.open("rewrite", "Rewrite bicycle again", "test", a); c.ID != 3 {
+		t.Errorf("Draft should be open for review again after merging, but got %+v", c)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	diff := "diff --git a/home.md b/home.md\n--- a/home.md\n+++ b/home.md\n@@ -1 +1 @@\n-Home.\n+Welcome.\n"
+	expected := []string{"active", "active", "active", "info", "danger", "success"}
+	lines := diffLines(diff)
+	if len(lines) != len(expected) {
+		t.Fatalf("Number of lines should equal %d, but is %d", len(expected), len(lines))
+	}
+	for i, line := range lines {
+		if line.Class != expected[i] {
+			t.Errorf("Class of >%s< should equal >%s<, but is >%s<", line.Text, expected[i], line.Class)
+		}
+	}
+}
+
+func TestDraftWrap(t *testing.T) {
+	dir := initRepo()
+	defer discardRepo(dir)
+	state, _ := ioutil.TempDir("", "goiki-changes")
+	defer os.RemoveAll(state)
+	changes, _ = loadChangeStore(filepath.Join(state, "changes.json"))
+	defer func() { changes = nil }()
+	conf.Auth = map[string]user{"goiki": {Username: "goiki", Password: "{SHA}4v0+mLtvlX3qyy5ISrQU5mw0Yhg="}}
+	defer func() { conf = config{} }()
+	authenticator = auth.NewBasicAuthenticator("goiki", secret)
+	defer func() { authenticator = nil }()
+
+	handler := draftWrap(func(w http.ResponseWriter, r *http.Request) {})
+	tests := map[string]int{
+		"/view/home":                                    http.StatusOK,
+		"/view/home?draft=":                             http.StatusOK,
+		"/view/home?revision=HEAD":                      http.StatusOK,
+		"/view/home?revision=4f0a9c1":                   http.StatusOK,
+		"/view/home?draft=rewrite":                      http.StatusUnauthorized,
+		"/view/home?revision=drafts/rewrite":            http.StatusUnauthorized,
+		"/view/home?revision=heads/drafts/rewrite":      http.StatusUnauthorized,
+		"/view/home?revision=refs/heads/drafts/rewrite": http.StatusUnauthorized,
+		"/view/home?revision=HEAD~1":                    http.StatusUnauthorized,
+		"/file/home.png?revision=heads/drafts/rewrite":  http.StatusUnauthorized,
+	}
+	for target, expected := range tests {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest("GET", target, nil))
+		if w.Code != expected {
+			t.Errorf("Reading %s should answer %d, but answered %d", target, expected, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/view/home?draft=rewrite", nil)
+	r.SetBasicAuth("goiki", "goiki")
+	handler(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("Users should read drafts, but got %d", w.Code)
+	}
+}
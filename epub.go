@@ -0,0 +1,400 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var (
+	xmlEntity = regexp.MustCompile(`&[a-zA-Z][a-zA-Z0-9]*;`)
+
+	epubTemplates = template.Must(template.New("epub").Parse(`
+{{define "container"}}<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+{{end}}
+{{define "package"}}<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="id">{{.ID}}</dc:identifier>
+    <dc:title>{{html .Title}}</dc:title>
+    <dc:language>en</dc:language>
+    <meta property="dcterms:modified">{{.Modified}}</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    {{range .Chapters}}<item id="{{.ID}}" href="{{.ID}}.xhtml" media-type="application/xhtml+xml"/>
+    {{end}}{{range .Attachments}}<item id="{{.ID}}" href="{{html .Href}}" media-type="{{.MediaType}}"/>
+    {{end}}
+  </manifest>
+  <spine>
+    {{range .Chapters}}<itemref idref="{{.ID}}"/>
+    {{end}}
+  </spine>
+</package>
+{{end}}
+{{define "nav"}}<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="en" xml:lang="en">
+<head>
+  <meta charset="UTF-8"/>
+  <title>{{html .Title}}</title>
+</head>
+<body>
+  <nav epub:type="toc" id="toc">
+    <h1>{{html .Title}}</h1>
+    {{template "toc" .Tree.Children}}
+  </nav>
+</body>
+</html>
+{{end}}
+{{define "toc"}}<ol>
+{{range .}}<li>{{if .Chapter}}<a href="{{.Chapter}}.xhtml">{{html .Name}}</a>{{else}}<span>{{html .Name}}</span>{{end}}{{if .Children}}
+{{template "toc" .Children}}{{end}}</li>
+{{end}}</ol>{{end}}
+{{define "chapter"}}<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="en" xml:lang="en">
+<head>
+  <meta charset="UTF-8"/>
+  <title>{{html .Title}}</title>
+</head>
+<body>
+  <section id="{{.ID}}">
+{{.Body}}
+  </section>
+</body>
+</html>
+{{end}}`))
+)
+
+// epubNode is a page or a directory in the page tree of a book.
+type epubNode struct {
+	Name     string
+	Title    string
+	Chapter  string
+	Children []*epubNode
+}
+
+type epubChapter struct {
+	ID    string
+	Title string
+	Body  string
+}
+
+type epubAttachment struct {
+	ID        string
+	File      string
+	Href      string
+	MediaType string
+}
+
+type epubBook struct {
+	ID          string
+	Title       string
+	Modified    string
+	Tree        *epubNode
+	Chapters    []*epubChapter
+	Attachments []*epubAttachment
+}
+
+// inPrefix tells whether the page title is prefix or below it.
+func inPrefix(title string, prefix string) bool {
+	return len(prefix) == 0 || title == prefix || strings.HasPrefix(title, prefix+"/")
+}
+
+// child returns the child of n with name, adding it if needed.
+func (n *epubNode) child(name string) *epubNode {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	c := &epubNode{Name: name}
+	n.Children = append(n.Children, c)
+	return c
+}
+
+// sort orders the page tree: the index page of a directory comes first, the
+// other pages and directories follow by name.
+func (n *epubNode) sort() {
+	sort.Slice(n.Children, func(i, j int) bool {
+		a, b := n.Children[i], n.Children[j]
+		if (a.Name == conf.IndexPage) != (b.Name == conf.IndexPage) {
+			return a.Name == conf.IndexPage
+		}
+		return a.Name < b.Name
+	})
+	for _, c := range n.Children {
+		c.sort()
+	}
+}
+
+// number assigns chapters to the pages of the tree in reading order.
+func (n *epubNode) number(chapters map[string]string, order *[]string) {
+	if len(n.Title) > 0 {
+		n.Chapter = fmt.Sprintf("page-%d", len(*order)+1)
+		chapters[n.Title] = n.Chapter
+		*order = append(*order, n.Title)
+	}
+	for _, c := range n.Children {
+		c.number(chapters, order)
+	}
+}
+
+// xhtmlEntities replaces HTML entities unknown to XML by their characters.
+func xhtmlEntities(content []byte) []byte {
+	return xmlEntity.ReplaceAllFunc(content, func(entity []byte) []byte {
+		switch string(entity) {
+		case "&amp;", "&lt;", "&gt;", "&quot;", "&apos;":
+			return entity
+		}
+		return []byte(html.EscapeString(html.UnescapeString(string(entity))))
+	})
+}
+
+// wikiTarget resolves a link on page to a file in the wiki, if it points into
+// the wiki at all. Pages are returned by their title.
+func wikiTarget(page string, link string) (string, string, bool) {
+	target, fragment := link, ""
+	if i := strings.Index(target, "#"); i >= 0 {
+		target, fragment = target[:i], target[i:]
+	}
+	if i := strings.Index(target, "?"); i >= 0 {
+		target = target[:i]
+	}
+	target, err := url.PathUnescape(target)
+	if err != nil {
+		return "", "", false
+	}
+	dir := strings.HasSuffix(target, "/")
+	switch {
+	case target == "/":
+		return conf.IndexPage, fragment, true
+	case strings.HasPrefix(target, "/view/"):
+		target = path.Clean(strings.TrimPrefix(target, "/view/"))
+	case len(target) == 0 || strings.HasPrefix(target, "/") || strings.Contains(target, ":"):
+		return "", "", false
+	default:
+		target = path.Join(path.Dir(page), target)
+	}
+	if dir || target == "." {
+		target = path.Join(target, conf.IndexPage)
+	}
+	if strings.HasPrefix(target, "../") || target == ".." {
+		return "", "", false
+	}
+	return target, fragment, true
+}
+
+// writeEpub bundles the pages at or below prefix at revision into an EPUB 3
+// book, with a table of contents ordered by the page tree. Links between the
+// pages lead to their chapters and the files they refer to are included.
+func writeEpub(w io.Writer, prefix string, revision string) error {
+	prefix = strings.Trim(prefix, "/")
+	files, err := store.Files(revision)
+	if err != nil {
+		return err
+	}
+	exists := make(map[string]bool)
+	tree := &epubNode{}
+	for _, file := range files {
+		exists[file] = true
+		if !strings.HasSuffix(file, "."+conf.FileExtension) || !inPrefix(title(file), prefix) {
+			continue
+		}
+		n := tree
+		for _, name := range strings.Split(title(file), "/") {
+			n = n.child(name)
+		}
+		n.Title = title(file)
+	}
+	// The book starts at the prefix, which may be a page itself.
+	if len(prefix) > 0 {
+		for _, name := range strings.Split(prefix, "/") {
+			if len(tree.Children) != 1 || tree.Children[0].Name != name {
+				break
+			}
+			tree = tree.Children[0]
+		}
+		if len(tree.Title) > 0 {
+			tree = &epubNode{Children: []*epubNode{tree}}
+		}
+	}
+	tree.sort()
+	chapters := make(map[string]string)
+	var order []string
+	tree.number(chapters, &order)
+	if len(order) == 0 {
+		return fmt.Errorf("There are no pages at %s", prefix)
+	}
+
+	id := make([]byte, 16)
+	if _, err = rand.Read(id); err != nil {
+		return err
+	}
+	book := &epubBook{
+		ID:       fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:]),
+		Title:    conf.Name,
+		Modified: time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		Tree:     tree,
+	}
+	if len(prefix) > 0 {
+		book.Title += ": " + prefix
+	}
+
+	attachments := make(map[string]*epubAttachment)
+	for _, t := range order {
+		content, err := store.Read(fileName(t), revision)
+		if err != nil {
+			return err
+		}
+		rendering, _ := renderPage(t, content, revision, nil)
+		body := exportLink.ReplaceAllFunc(xhtmlEntities(rendering), func(match []byte) []byte {
+			m := exportLink.FindSubmatch(match)
+			target, fragment, ok := wikiTarget(t, html.UnescapeString(string(m[2])))
+			if !ok {
+				return match
+			}
+			if chapter, ok := chapters[target]; ok {
+				if len(fragment) == 0 {
+					fragment = "#" + chapter
+				}
+				return []byte(fmt.Sprintf(`%s="%s.xhtml%s"`, m[1], chapter, fragment))
+			}
+			if !exists[target] || strings.HasSuffix(target, "."+conf.FileExtension) {
+				return match
+			}
+			a, ok := attachments[target]
+			if !ok {
+				mediaType := mime.TypeByExtension(path.Ext(target))
+				if len(mediaType) == 0 {
+					mediaType = "application/octet-stream"
+				}
+				a = &epubAttachment{
+					ID:        fmt.Sprintf("file-%d", len(attachments)+1),
+					File:      target,
+					Href:      "files/" + target,
+					MediaType: strings.SplitN(mediaType, ";", 2)[0],
+				}
+				attachments[target] = a
+				book.Attachments = append(book.Attachments, a)
+			}
+			return []byte(fmt.Sprintf(`%s="%s"`, m[1], html.EscapeString(pageURL(a.Href))))
+		})
+		book.Chapters = append(book.Chapters, &epubChapter{ID: chapters[t], Title: pageTitle(t, content), Body: string(body)})
+	}
+
+	z := zip.NewWriter(w)
+	// The mimetype comes first and uncompressed, so that it can be sniffed.
+	f, err := z.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err = io.WriteString(f, "application/epub+zip"); err != nil {
+		return err
+	}
+	write := func(name string, tmpl string, data interface{}) error {
+		f, err := z.Create(name)
+		if err != nil {
+			return err
+		}
+		return epubTemplates.ExecuteTemplate(f, tmpl, data)
+	}
+	if err = write("META-INF/container.xml", "container", nil); err != nil {
+		return err
+	}
+	if err = write("OEBPS/content.opf", "package", book); err != nil {
+		return err
+	}
+	if err = write("OEBPS/nav.xhtml", "nav", book); err != nil {
+		return err
+	}
+	for _, c := range book.Chapters {
+		if err = write("OEBPS/"+c.ID+".xhtml", "chapter", c); err != nil {
+			return err
+		}
+	}
+	for _, a := range book.Attachments {
+		content, err := store.Read(a.File, revision)
+		if err != nil {
+			return err
+		}
+		if f, err = z.Create("OEBPS/" + a.Href); err != nil {
+			return err
+		}
+		if _, err = f.Write(content); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+// epubCommand runs "epub <file> [prefix] [--revision X]".
+func epubCommand(args []string) error {
+	flags := flag.NewFlagSet("epub", flag.ContinueOnError)
+	revision := flags.String("revision", "HEAD", "Revision of the wiki to export")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New("Usage: goiki [flags] epub <file> [prefix] [--revision X]")
+	}
+	prefix := ""
+	if len(args) == 2 {
+		prefix = args[1]
+	}
+	var book bytes.Buffer
+	if err = writeEpub(&book, prefix, *revision); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(args[0], book.Bytes(), 0644)
+}
+
+// epubHandler serves the pages below /export/epub/<prefix> as an EPUB book.
+func epubHandler(w http.ResponseWriter, r *http.Request) {
+	prefix := strings.Trim(strings.TrimPrefix(r.URL.Path, "/export/epub"), "/")
+	if strings.Contains(prefix, "..") {
+		http.NotFound(w, r)
+		return
+	}
+	revision := r.FormValue("revision")
+	if revision == "" {
+		revision = "HEAD"
+	}
+
+	var book bytes.Buffer
+	if err := writeEpub(&book, prefix, revision); err != nil {
+		log.Printf("Unable to export %s as EPUB: %v\n", prefix, err)
+		http.NotFound(w, r)
+		return
+	}
+	name := strings.Replace(prefix, "/", "-", -1)
+	if len(name) == 0 {
+		name = conf.Name
+	}
+	w.Header().Set("Content-Type", "application/epub+zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.epub"`, name))
+	w.Write(book.Bytes())
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestWriteEpub(t *testing.T) {
+	dir := initRepo()
+	defer discardRepo(dir)
+	conf = config{Name: "Goiki", FileExtension: "md", IndexPage: "home"}
+	defer func() { conf = config{} }()
+
+	a := author{Name: "Test", Email: "test@example.com"}
+	store.Write("home.md", []byte("Not in the book."), "Add home", a)
+	store.Write("runbooks/home.md", []byte("See [db/restore]() and [\"disks\"](/view/runbooks/disks)."), "Add runbooks", a)
+	store.Write("runbooks/disks.md", []byte("![Disk](disk.png)"), "Add disks", a)
+	store.Write("runbooks/disk.png", []byte("PNG"), "Add disk", a)
+	store.Write("runbooks/db/restore.md", []byte("Back to [the runbooks](/view/runbooks/).\n\n- [x] stop writes\n- [ ] restore the dump"), "Add restore", a)
+
+	var book bytes.Buffer
+	if err := writeEpub(&book, "runbooks", "HEAD"); err != nil {
+		t.Fatalf("Unable to write the book: %v", err)
+	}
+	z, err := zip.NewReader(bytes.NewReader(book.Bytes()), int64(book.Len()))
+	if err != nil {
+		t.Fatalf("Book should be a zip: %v", err)
+	}
+	if z.File[0].Name != "mimetype" || z.File[0].Method != zip.Store {
+		t.Errorf("Book should start with the uncompressed mimetype, but starts with %s", z.File[0].Name)
+	}
+
+	files := make(map[string]string)
+	for _, f := range z.File {
+		r, _ := f.Open()
+		content, _ := ioutil.ReadAll(r)
+		files[f.Name] = string(content)
+		if strings.HasSuffix(f.Name, ".xhtml") || strings.HasSuffix(f.Name, ".opf") || strings.HasSuffix(f.Name, ".xml") {
+			d := xml.NewDecoder(bytes.NewReader(content))
+			for err == nil {
+				_, err = d.Token()
+			}
+			if err != io.EOF {
+				t.Errorf("%s should be well-formed XML: %v", f.Name, err)
+			}
+			err = nil
+		}
+	}
+
+	// Chapters follow the page tree: the index page first.
+	nav := files["OEBPS/nav.xhtml"]
+	order := []string{`"page-1.xhtml">home`, `<span>db</span>`, `"page-2.xhtml">restore`, `"page-3.xhtml">disks`}
+	last := -1
+	for _, entry := range order {
+		i := strings.Index(nav, entry)
+		if i <= last {
+			t.Errorf("Navigation should list >%s< next, but is >%s<", entry, nav)
+		}
+		last = i
+	}
+	if strings.Contains(nav, "Not in the book") || len(files) != 8 {
+		t.Errorf("Book should only contain the runbooks, but has %d files", len(files))
+	}
+
+	home := files["OEBPS/page-1.xhtml"]
+	for _, expected := range []string{`href="page-2.xhtml#page-2"`, `href="page-3.xhtml#page-3"`, "“disks”"} {
+		if !strings.Contains(home, expected) {
+			t.Errorf("First chapter should contain >%s<, but is >%s<", expected, home)
+		}
+	}
+	if !strings.Contains(files["OEBPS/page-2.xhtml"], `href="page-1.xhtml#page-1"`) {
+		t.Errorf("Link to the runbooks directory should lead to its index page")
+	}
+	if strings.Count(files["OEBPS/page-2.xhtml"], `type="checkbox"`) != 2 {
+		t.Errorf("Tasks should be shown as checkboxes, but are >%s<", files["OEBPS/page-2.xhtml"])
+	}
+	if !strings.Contains(files["OEBPS/page-3.xhtml"], `src="files/runbooks/disk.png"`) || files["OEBPS/files/runbooks/disk.png"] != "PNG" {
+		t.Errorf("Image should be included in the book")
+	}
+	if !strings.Contains(files["OEBPS/content.opf"], `href="files/runbooks/disk.png" media-type="image/png"`) {
+		t.Errorf("Image should be in the manifest, but is >%s<", files["OEBPS/content.opf"])
+	}
+
+	if err = writeEpub(&book, "missing", "HEAD"); err == nil {
+		t.Errorf("Book without pages should fail")
+	}
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// exporting is set while a static copy of the wiki is exported, so that
+	// the templates can leave out what only works on the server.
+	exporting  bool
+	exportLink = regexp.MustCompile(`(href|src)="([^"]*)"`)
+)
+
+// parseArgs parses the flags of a command given before, between or after its
+// arguments, which are returned.
+func parseArgs(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+// exportCommand runs "export <outdir> [--revision X]".
+func exportCommand(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	revision := flags.String("revision", "HEAD", "Revision of the wiki to export")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return errors.New("Usage: goiki [flags] export <outdir> [--revision X]")
+	}
+	return exportWiki(args[0], *revision)
+}
+
+// exportWiki writes a read-only copy of the wiki at revision to outdir, for
+// static hosting. Pages are rendered like viewHandler does, with links
+// rewritten to the exported files. Other files, such as images, are copied as
+// they are. Directories without an index page get a listing instead.
+func exportWiki(outdir string, revision string) error {
+	exporting = true
+	defer func() { exporting = false }()
+
+	files, err := store.Files(revision)
+	if err != nil {
+		return err
+	}
+	pages := make(map[string]bool)
+	dirs := map[string]bool{"": true}
+	for _, file := range files {
+		if !strings.HasSuffix(file, "."+conf.FileExtension) || hiddenFile(file) {
+			continue
+		}
+		pages[title(file)] = true
+		for dir := path.Dir(file); dir != "."; dir = path.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+
+	for _, file := range files {
+		if hiddenFile(file) {
+			continue
+		}
+		content, err := store.Read(file, revision)
+		if err != nil {
+			return err
+		}
+		if !strings.HasSuffix(file, "."+conf.FileExtension) {
+			if err = exportFile(outdir, file, content); err != nil {
+				return err
+			}
+			continue
+		}
+		body, _ := renderPage(title(file), content, revision, nil)
+		content, err = exportPage(title(file), pageTitle(title(file), content), body, pages)
+		if err != nil {
+			return err
+		}
+		if err = exportFile(outdir, title(file)+".html", content); err != nil {
+			return err
+		}
+	}
+
+	for dir := range dirs {
+		if err = exportDir(outdir, dir, pages, dirs); err != nil {
+			return err
+		}
+	}
+	return exportStatic(outdir)
+}
+
+func hiddenFile(file string) bool {
+	for _, part := range strings.Split(file, "/") {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+func exportFile(outdir string, file string, content []byte) error {
+	file = filepath.Join(outdir, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, content, 0644)
+}
+
+// exportPage renders the view template for a page with name and body and
+// rewrites its links for the static copy.
+func exportPage(title string, name string, body []byte, pages map[string]bool) ([]byte, error) {
+	var out bytes.Buffer
+	p := &page{Title: title, Name: name, Theme: conf.Theme, Body: string(body), SiteName: conf.Name}
+	if err := templates.ExecuteTemplate(&out, "view", p); err != nil {
+		return nil, err
+	}
+	return exportLink.ReplaceAllFunc(out.Bytes(), func(match []byte) []byte {
+		m := exportLink.FindSubmatch(match)
+		return []byte(fmt.Sprintf(`%s="%s"`, m[1], exportTarget(title, string(m[2]), pages)))
+	}), nil
+}
+
+// exportTarget rewrites a link on page to point to the exported files:
+// /view/ links and relative links to pages go to the .html files, static
+// content goes to the copy of it, and anything else is left alone.
+func exportTarget(page string, link string, pages map[string]bool) string {
+	target, fragment := link, ""
+	if i := strings.Index(target, "#"); i >= 0 {
+		target, fragment = target[:i], target[i:]
+	}
+	if i := strings.Index(target, "?"); i >= 0 {
+		target = target[:i]
+	}
+	up := strings.Repeat("../", strings.Count(page, "/"))
+
+	switch {
+	case target == "/":
+		return up + conf.IndexPage + ".html" + fragment
+	case strings.HasPrefix(target, "/view/"):
+		target = strings.TrimPrefix(target, "/view/")
+		if len(target) == 0 || strings.HasSuffix(target, "/") {
+			target += conf.IndexPage
+		}
+		return up + target + ".html" + fragment
+	case strings.HasPrefix(target, "/static/"):
+		return up + strings.TrimPrefix(target, "/") + fragment
+	case len(target) == 0 || strings.HasPrefix(target, "/") || strings.Contains(target, ":"):
+		return link
+	}
+
+	// Relative links are resolved the way the browser resolves them on
+	// /view/<page>.
+	resolved, err := url.PathUnescape(target)
+	if err != nil {
+		return link
+	}
+	resolved = path.Join(path.Dir(page), resolved)
+	if strings.HasSuffix(target, "/") {
+		return target + conf.IndexPage + ".html" + fragment
+	}
+	if pages[resolved] {
+		return target + ".html" + fragment
+	}
+	return link
+}
+
+// exportDir writes index.html for dir, which is the index page of dir if
+// there is one, or a listing of its pages and subdirectories otherwise.
+func exportDir(outdir string, dir string, pages map[string]bool, dirs map[string]bool) error {
+	index := path.Join(dir, conf.IndexPage)
+	if pages[index] {
+		if conf.IndexPage == "index" {
+			return nil
+		}
+		content, err := ioutil.ReadFile(filepath.Join(outdir, filepath.FromSlash(index)+".html"))
+		if err != nil {
+			return err
+		}
+		return exportFile(outdir, path.Join(dir, "index.html"), content)
+	}
+
+	var entries []string
+	for p := range pages {
+		if path.Dir(p) == dir || (dir == "" && !strings.Contains(p, "/")) {
+			entries = append(entries, p)
+		}
+	}
+	for d := range dirs {
+		if len(d) > 0 && (path.Dir(d) == dir || (dir == "" && !strings.Contains(d, "/"))) {
+			entries = append(entries, d+"/")
+		}
+	}
+	sort.Strings(entries)
+
+	var body bytes.Buffer
+	heading := dir
+	if len(heading) == 0 {
+		heading = conf.Name
+	}
+	fmt.Fprintf(&body, "<h1>%s</h1>\n<ul>\n", html.EscapeString(heading))
+	for _, entry := range entries {
+		name := path.Base(entry)
+		if strings.HasSuffix(entry, "/") {
+			name += "/"
+		}
+		fmt.Fprintf(&body, "<li><a href=\"/view/%s\">%s</a></li>\n", html.EscapeString(entry), html.EscapeString(name))
+	}
+	body.WriteString("</ul>\n")
+
+	content, err := exportPage(index, heading, body.Bytes(), pages)
+	if err != nil {
+		return err
+	}
+	if err = exportFile(outdir, index+".html", content); err != nil {
+		return err
+	}
+	return exportFile(outdir, path.Join(dir, "index.html"), content)
+}
+
+// exportStatic copies the static content, from static_dir if configured or
+// from the embedded content otherwise.
+func exportStatic(outdir string) error {
+	if len(conf.StaticDir) > 0 {
+		return filepath.Walk(conf.StaticDir, func(file string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(conf.StaticDir, file)
+			if err != nil {
+				return err
+			}
+			content, err := ioutil.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			return exportFile(outdir, path.Join("static", filepath.ToSlash(rel)), content)
+		})
+	}
+	for name, f := range data {
+		if f.isDir {
+			continue
+		}
+		file, err := static.Open(name)
+		if err != nil {
+			return err
+		}
+		content, err := ioutil.ReadAll(file)
+		if err != nil {
+			return err
+		}
+		if err = exportFile(outdir, name, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
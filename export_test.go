@@ -0,0 +1,86 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportWiki(t *testing.T) {
+	dir := initRepo()
+	defer discardRepo(dir)
+	conf = config{Name: "Goiki", FileExtension: "md", IndexPage: "home", Theme: "default"}
+	defer func() { conf = config{} }()
+	loadTemplates()
+	out, _ := ioutil.TempDir("", "goiki-export")
+	defer os.RemoveAll(out)
+
+	a := author{Name: "Test", Email: "test@example.com"}
+	store.Write("home.md", []byte("Life is like riding a [life/bicycle]()."), "Add home", a)
+	store.Write("life/bicycle.md", []byte("Back [home](/view/home), or [somewhere](http://example.com)."), "Add bicycle", a)
+	store.Write("life/wheel.png", []byte("PNG"), "Add wheel", a)
+
+	if err := exportWiki(out, "HEAD"); err != nil {
+		t.Fatalf("Unable to export: %v", err)
+	}
+
+	read := func(file string) string {
+		content, err := ioutil.ReadFile(filepath.Join(out, file))
+		if err != nil {
+			t.Errorf("File %s should be exported (error: %v)", file, err)
+		}
+		return string(content)
+	}
+	home := read("home.html")
+	for _, expected := range []string{`href="life/bicycle.html"`, `href="static/css/bootswatch-default.min.css"`} {
+		if !strings.Contains(home, expected) {
+			t.Errorf("home.html should contain >%s<", expected)
+		}
+	}
+	if strings.Contains(home, "/edit/") || strings.Contains(home, "/search/") {
+		t.Errorf("home.html should not link to the editor or search")
+	}
+	bicycle := read("life/bicycle.html")
+	for _, expected := range []string{`href="../home.html"`, `href="http://example.com"`, `href="../static/css/bootswatch-default.min.css"`} {
+		if !strings.Contains(bicycle, expected) {
+			t.Errorf("life/bicycle.html should contain >%s<", expected)
+		}
+	}
+	if png := read("life/wheel.png"); png != "PNG" {
+		t.Errorf("life/wheel.png should be copied, but is >%s<", png)
+	}
+	if index := read("index.html"); index != home {
+		t.Errorf("index.html should be a copy of home.html")
+	}
+	listing := read("life/home.html")
+	if !strings.Contains(listing, `<a href="../life/bicycle.html">bicycle</a>`) || listing != read("life/index.html") {
+		t.Errorf("life/home.html and life/index.html should list the pages in life, but were >%s<", listing)
+	}
+	read("static/js/bootstrap.min.js")
+}
+
+func TestExportTarget(t *testing.T) {
+	conf.IndexPage = "home"
+	defer func() { conf = config{} }()
+	pages := map[string]bool{"home": true, "life/bicycle": true, "life/home": true}
+	links := map[string]string{
+		"/":                    "../home.html",
+		"/view/":               "../home.html",
+		"/view/life/":          "../life/home.html",
+		"/view/life/bicycle#x": "../life/bicycle.html#x",
+		"/static/css/a.css":    "../static/css/a.css",
+		"bicycle":              "bicycle.html",
+		"unknown":              "unknown",
+		"wheel.png":            "wheel.png",
+		"#top":                 "#top",
+		"mailto:a@example.com": "mailto:a@example.com",
+		"/favicon.ico":         "/favicon.ico",
+	}
+	for link, expected := range links {
+		if target := exportTarget("life/bicycle", link, pages); target != expected {
+			t.Errorf("Link >%s< should be rewritten to >%s<, but was >%s<", link, expected, target)
+		}
+	}
+}
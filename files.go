@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	backupDir    = ".backups"
+	backupLog    = "log"
+	backupFormat = "20060102T150405.000000000"
+)
+
+// errNoHistory is returned by storage backends that keep no revisions.
+var errNoHistory = errors.New("No page history is kept")
+
+// fileStorage reads and writes the data directory directly, without Git. If
+// backups are enabled, every version written is also copied to a timestamped
+// file under .backups, along with a log of who wrote it and why, which serves
+// as a lightweight history. The timestamps are the revisions.
+type fileStorage struct {
+	sync.Mutex
+	dir     string
+	backups bool
+	now     func() time.Time
+}
+
+func openFileStorage(dir string, backups bool) (*fileStorage, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	return &fileStorage{dir: dir, backups: backups, now: time.Now}, nil
+}
+
+func (s *fileStorage) path(file string, revision string) (string, error) {
+	if revision == "" || revision == "HEAD" {
+		return dataPath(s.dir, file), nil
+	}
+	if !s.backups {
+		return "", errNoHistory
+	}
+	if _, err := time.Parse(backupFormat, revision); err != nil {
+		return "", fmt.Errorf("Unknown revision %s", revision)
+	}
+	return filepath.Join(s.dir, backupDir, file, revision), nil
+}
+
+func (s *fileStorage) Read(file string, revision string) ([]byte, error) {
+	path, err := s.path(file, revision)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(path)
+}
+
+// Blob hashes the content the way Git hashes blobs.
+func (s *fileStorage) Blob(file string, revision string) (string, error) {
+	content, err := s.Read(file, revision)
+	if err != nil {
+		return "", err
+	}
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+func (s *fileStorage) Write(file string, data []byte, message string, author author) error {
+	s.Lock()
+	defer s.Unlock()
+	datapath := dataPath(s.dir, file)
+	err := os.MkdirAll(filepath.Dir(datapath), 0777)
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(datapath, data, 0600)
+	if err != nil || !s.backups {
+		return err
+	}
+
+	backups := filepath.Join(s.dir, backupDir, file)
+	if err = os.MkdirAll(backups, 0777); err != nil {
+		return err
+	}
+	when := author.When
+	if when.IsZero() {
+		when = s.now()
+	}
+	revision := when.UTC().Format(backupFormat)
+	if err = ioutil.WriteFile(filepath.Join(backups, revision), data, 0600); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(backups, backupLog), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	message = strings.Replace(strings.SplitN(message, "\n", 2)[0], "\t", " ", -1)
+	_, err = fmt.Fprintf(f, "%s\t%s\t%s\t%s\n", revision, author.Name, author.Email, message)
+	return err
+}
+
+func (s *fileStorage) Log(file string) ([]pageRevision, error) {
+	var revisions []pageRevision
+	if !s.backups {
+		return revisions, errNoHistory
+	}
+	f, err := os.Open(filepath.Join(s.dir, backupDir, file, backupLog))
+	if os.IsNotExist(err) {
+		return revisions, nil
+	} else if err != nil {
+		return revisions, err
+	}
+	defer f.Close()
+
+	now := s.now()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), "\t", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		when, err := time.Parse(backupFormat, fields[0])
+		if err != nil {
+			continue
+		}
+		revisions = append([]pageRevision{{
+			Title:       title(file),
+			Object:      fields[0],
+			Description: fields[3],
+			Author:      author{Name: fields[1], Email: fields[2]},
+			Timestamp:   relativeTime(when, now),
+		}}, revisions...)
+	}
+	return revisions, scanner.Err()
+}
+
+// Files lists the files in the data directory; revision is ignored since
+// backups are kept per file. Hidden files and directories are skipped.
+func (s *fileStorage) Files(revision string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(s.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != s.dir && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() {
+			file, err := filepath.Rel(s.dir, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(file))
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+func (s *fileStorage) Grep(keyword string) ([]searchResult, error) {
+	results := make([]searchResult, 0)
+	re := grepPattern(keyword)
+	files, err := s.Files("HEAD")
+	if err != nil {
+		return results, err
+	}
+	for _, file := range files {
+		if !strings.HasSuffix(file, "."+conf.FileExtension) {
+			continue
+		}
+		content, err := ioutil.ReadFile(dataPath(s.dir, file))
+		if err != nil {
+			return results, err
+		}
+		results = append(results, grepLines(re, file, content)...)
+	}
+	return results, nil
+}
+
+// Head identifies the current state of the data directory by the number of
+// files and the latest modification.
+func (s *fileStorage) Head() (string, error) {
+	files, err := s.Files("HEAD")
+	if err != nil || len(files) == 0 {
+		return "", err
+	}
+	var latest time.Time
+	for _, file := range files {
+		info, err := os.Stat(dataPath(s.dir, file))
+		if err != nil {
+			return "", err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	h := sha1.New()
+	fmt.Fprintf(h, "%d %d", len(files), latest.UnixNano())
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
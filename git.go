@@ -2,10 +2,15 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"github.com/VictorLowther/go-git/git"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 )
 
 var (
@@ -15,12 +20,22 @@ var (
 type author struct {
 	Name  string
 	Email string
+	// When the change was authored, if not now, e.g. when importing history.
+	When time.Time `json:"-"`
 }
 
 func (a *author) String() string {
 	return fmt.Sprintf("%s <%s>", a.Name, a.Email)
 }
 
+// time returns when the change was authored.
+func (a *author) time() time.Time {
+	if a.When.IsZero() {
+		return time.Now()
+	}
+	return a.When
+}
+
 type pageRevision struct {
 	Title       string
 	Object      string
@@ -39,12 +54,20 @@ func title(file string) string {
 }
 
 func gitExec(command string, args ...string) (*bytes.Buffer, error) {
+	return gitExecEnv(nil, command, args...)
+}
+
+// gitExecEnv is like gitExec, but adds env to the environment of git.
+func gitExecEnv(env []string, command string, args ...string) (*bytes.Buffer, error) {
 	res, out, stderr := repo.Git(command, args...)
+	if len(env) > 0 {
+		res.Env = append(os.Environ(), env...)
+	}
 	runErr := res.Run()
 	if runErr != nil {
 		return out, runErr
 	} else if stderr.Len() > 0 {
-		return out, fmt.Errorf(stderr.String())
+		return out, errors.New(stderr.String())
 	}
 	return out, nil
 }
@@ -53,15 +76,139 @@ func gitShow(file string, revision string) (*bytes.Buffer, error) {
 	return gitExec("show", fmt.Sprintf("%s:%s", revision, file))
 }
 
+// gitBlob returns the hash of the blob of file at revision.
+func gitBlob(file string, revision string) (string, error) {
+	out, err := gitExec("rev-parse", "--verify", "--quiet", fmt.Sprintf("%s:%s", revision, file))
+	return strings.TrimSpace(out.String()), err
+}
+
 func gitAdd(file string) (*bytes.Buffer, error) {
 	return gitExec("add", file)
 }
 
 func gitCommit(message string, author author) (*bytes.Buffer, error) {
-	if author.String() == "" {
-		return gitExec("commit", "-m", message)
+	args := []string{"-m", message}
+	if author.String() != "" {
+		args = append(args, "--author", author.String())
+	}
+	if !author.When.IsZero() {
+		args = append(args, "--date", author.When.Format(time.RFC3339))
 	}
-	return gitExec("commit", "-m", message, "--author", author.String())
+	return gitExec("commit", args...)
+}
+
+func gitPull(remote string, branch string, rebase bool) (*bytes.Buffer, error) {
+	strategy := "--no-rebase"
+	if rebase {
+		strategy = "--rebase"
+	}
+	return gitExec("pull", "--quiet", "--no-edit", strategy, remote, branch)
+}
+
+func gitPush(remote string, branch string) (*bytes.Buffer, error) {
+	return gitExec("push", "--quiet", remote, "HEAD:"+branch)
+}
+
+func gitConflicts() ([]string, error) {
+	out, err := gitExec("diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out.String()), nil
+}
+
+func gitAbort(rebase bool) (*bytes.Buffer, error) {
+	if rebase {
+		return gitExec("rebase", "--abort")
+	}
+	return gitExec("merge", "--abort")
+}
+
+// authorEnv sets the author of commits made by git plumbing commands.
+func authorEnv(author author) []string {
+	var env []string
+	if len(author.Name) > 0 {
+		env = append(env, "GIT_AUTHOR_NAME="+author.Name)
+	}
+	if len(author.Email) > 0 {
+		env = append(env, "GIT_AUTHOR_EMAIL="+author.Email)
+	}
+	if !author.When.IsZero() {
+		env = append(env, "GIT_AUTHOR_DATE="+author.When.Format(time.RFC3339))
+	}
+	return env
+}
+
+// gitWriteBranch commits data as file on branch, which is created from HEAD
+// if it does not exist. The commit is built in a temporary index, so neither
+// the working tree nor the current branch are touched.
+func gitWriteBranch(branch string, file string, data []byte, message string, author author) error {
+	parent, err := gitExec("rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
+	if err != nil {
+		if parent, err = gitExec("rev-parse", "--verify", "HEAD"); err != nil {
+			return err
+		}
+	}
+
+	tmp, err := ioutil.TempFile("", "goiki-draft")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	tmp.Close()
+	if err != nil {
+		return err
+	}
+	blob, err := gitExec("hash-object", "-w", "--no-filters", tmp.Name())
+	if err != nil {
+		return err
+	}
+
+	index := []string{"GIT_INDEX_FILE=" + tmp.Name() + ".index"}
+	defer os.Remove(tmp.Name() + ".index")
+	if _, err = gitExecEnv(index, "read-tree", strings.TrimSpace(parent.String())); err != nil {
+		return err
+	}
+	cacheinfo := fmt.Sprintf("100644,%s,%s", strings.TrimSpace(blob.String()), filepath.ToSlash(file))
+	if _, err = gitExecEnv(index, "update-index", "--add", "--cacheinfo", cacheinfo); err != nil {
+		return err
+	}
+	tree, err := gitExecEnv(index, "write-tree")
+	if err != nil {
+		return err
+	}
+	commit, err := gitExecEnv(authorEnv(author), "commit-tree", strings.TrimSpace(tree.String()),
+		"-p", strings.TrimSpace(parent.String()), "-m", message)
+	if err != nil {
+		return err
+	}
+	_, err = gitExec("update-ref", "refs/heads/"+branch, strings.TrimSpace(commit.String()))
+	return err
+}
+
+// gitDiff shows the changes made on branch since it forked off HEAD.
+func gitDiff(branch string) (*bytes.Buffer, error) {
+	return gitExec("diff", "HEAD..."+branch)
+}
+
+// gitMerge merges branch into the current branch. A merge that runs into
+// conflicts is aborted and the conflicting files are returned in the error.
+func gitMerge(branch string, message string, author author) error {
+	_, err := gitExecEnv(authorEnv(author), "merge", "--quiet", "--no-ff", "--no-edit", "-m", message, branch)
+	if err == nil {
+		return nil
+	}
+	conflicts, _ := gitConflicts()
+	if len(conflicts) == 0 {
+		return err
+	}
+	gitAbort(false)
+	return &mergeConflict{Files: conflicts}
+}
+
+func gitDeleteBranch(branch string) (*bytes.Buffer, error) {
+	return gitExec("branch", "--quiet", "-D", branch)
 }
 
 func gitLog(file string) ([]pageRevision, error) {
@@ -19,6 +19,7 @@ func initRepo() string {
 	if err != nil {
 		panic(err)
 	}
+	store = &execStorage{dir: dir}
 	return dir
 }
 
@@ -33,6 +34,8 @@ func discardRepo(dir string) {
 func TestGit(t *testing.T) {
 	dir := initRepo()
 	defer discardRepo(dir)
+	conf.FileExtension = "txt"
+	defer func() { conf = config{} }()
 
 	file := "test.txt"
 	data := "Testing adding and committing."
@@ -70,3 +73,110 @@ func TestGit(t *testing.T) {
 		t.Errorf(`Number of results returned should equal 1, but was %d`, len(results))
 	}
 }
+
+func runGit(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+}
+
+func TestGitSync(t *testing.T) {
+	remote, _ := ioutil.TempDir("./", "git-remote")
+	defer discardRepo(remote)
+	runGit(t, remote, "init", "--bare", "--quiet")
+	remote, _ = filepath.Abs(remote)
+
+	dir := initRepo()
+	defer discardRepo(dir)
+	defer func() { conf = config{} }()
+	conf.Sync = syncConfig{Remote: remote, Branch: "master", Strategy: "rebase"}
+
+	author := author{Name: "Test", Email: "test@example.com"}
+	ioutil.WriteFile(filepath.Join(dir, "local.txt"), []byte("local"), 0600)
+	gitAdd("local.txt")
+	gitCommit("Add local page", author)
+	if err := syncPush(); err != nil {
+		t.Fatalf("Unable to push to %s: %v", remote, err)
+	}
+
+	// Another clone of the remote adds a page and changes the local one.
+	clone, _ := ioutil.TempDir("./", "git-clone")
+	defer discardRepo(clone)
+	runGit(t, clone, "clone", "--quiet", remote, ".")
+	ioutil.WriteFile(filepath.Join(clone, "remote.txt"), []byte("remote"), 0600)
+	ioutil.WriteFile(filepath.Join(clone, "local.txt"), []byte("changed remotely"), 0600)
+	runGit(t, clone, "add", ".")
+	runGit(t, clone, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "Remote change")
+	runGit(t, clone, "push", "--quiet", "origin", "HEAD:master")
+
+	if err := syncNow(); err != nil {
+		t.Fatalf("Unable to sync with %s: %v", remote, err)
+	}
+	content, _ := gitShow("remote.txt", "HEAD")
+	if content.String() != "remote" {
+		t.Errorf(`Content of remote.txt should equal "remote" after pull, but was "%s"`, content.String())
+	}
+
+	// Conflicting changes abort the pull and are reported.
+	ioutil.WriteFile(filepath.Join(dir, "local.txt"), []byte("changed locally"), 0600)
+	gitAdd("local.txt")
+	gitCommit("Change local page", author)
+	ioutil.WriteFile(filepath.Join(clone, "local.txt"), []byte("changed remotely again"), 0600)
+	runGit(t, clone, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "--quiet", "-am", "Conflicting change")
+	runGit(t, clone, "push", "--quiet", "origin", "HEAD:master")
+
+	if err := syncPull(); err == nil {
+		t.Errorf("Pull of conflicting changes should fail")
+	}
+	if len(syncs.Conflicts) != 1 || syncs.Conflicts[0] != "local.txt" {
+		t.Errorf("Conflicts should equal [local.txt], but were %v", syncs.Conflicts)
+	}
+	content, _ = gitShow("local.txt", "HEAD")
+	if content.String() != "changed locally" {
+		t.Errorf(`Content of local.txt should equal "changed locally" after an aborted pull, but was "%s"`, content.String())
+	}
+}
+
+func TestCheckHead(t *testing.T) {
+	dir := initRepo()
+	defer discardRepo(dir)
+	defer func() { heads = headWatch{} }()
+
+	invalidated := 0
+	onHeadChange(func() { invalidated++ })
+	checkHead()
+	invalidated = 0
+
+	ioutil.WriteFile(filepath.Join(dir, "test.txt"), []byte("external"), 0600)
+	runGit(t, dir, "add", "test.txt")
+	runGit(t, dir, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "External commit")
+
+	if !checkHead() || invalidated != 1 {
+		t.Errorf("An external commit should be noticed once, but invalidated %d times", invalidated)
+	}
+	if checkHead() || invalidated != 1 {
+		t.Errorf("An unchanged HEAD should not invalidate, but invalidated %d times", invalidated)
+	}
+}
+
+func TestGitBlob(t *testing.T) {
+	dir := initRepo()
+	defer discardRepo(dir)
+
+	file := "test.txt"
+	ioutil.WriteFile(filepath.Join(dir, file), []byte("blob"), 0600)
+	gitAdd(file)
+	gitCommit("Test commit", author{Name: "Test", Email: "test@example.com"})
+
+	// The hash of a blob only depends on its content.
+	hash := "43ae31d836edd668d12fb87a494e340c2c5a8698"
+	blob, err := gitBlob(file, "HEAD")
+	if err != nil || blob != hash {
+		t.Errorf("Blob of %s should equal >%s<, but was >%s< (error: %v)", file, hash, blob, err)
+	}
+	if _, err = gitBlob("missing.txt", "HEAD"); err == nil {
+		t.Errorf("Blob of a missing file should not be found")
+	}
+}
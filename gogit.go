@@ -0,0 +1,647 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// goGitStorage is a pure Go Storage backed by go-git, so no git executable
+// is needed.
+type goGitStorage struct {
+	sync.Mutex
+	dir  string
+	repo *gogit.Repository
+	head string
+}
+
+func openGoGitStorage(dir string) (*goGitStorage, error) {
+	r, err := gogit.PlainOpen(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &goGitStorage{dir: dir, repo: r}, nil
+}
+
+// reopen opens the repo again, so that objects written by other processes,
+// such as packs received by a push, become visible.
+func (s *goGitStorage) reopen() error {
+	r, err := gogit.PlainOpen(s.dir)
+	if err != nil {
+		return err
+	}
+	s.repo = r
+	return nil
+}
+
+func (s *goGitStorage) tree(revision string) (*object.Tree, error) {
+	hash, err := s.repo.ResolveRevision(plumbing.Revision(revision))
+	if err == plumbing.ErrObjectNotFound || err == plumbing.ErrReferenceNotFound {
+		if s.reopen() == nil {
+			hash, err = s.repo.ResolveRevision(plumbing.Revision(revision))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	commit, err := s.repo.CommitObject(*hash)
+	if err == plumbing.ErrObjectNotFound && s.reopen() == nil {
+		commit, err = s.repo.CommitObject(*hash)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return commit.Tree()
+}
+
+func (s *goGitStorage) file(file string, revision string) (*object.File, error) {
+	tree, err := s.tree(revision)
+	if err != nil {
+		return nil, err
+	}
+	return tree.File(filepath.ToSlash(file))
+}
+
+func (s *goGitStorage) Read(file string, revision string) ([]byte, error) {
+	s.Lock()
+	defer s.Unlock()
+	f, err := s.file(file, revision)
+	if err != nil {
+		return nil, err
+	}
+	content, err := f.Contents()
+	return []byte(content), err
+}
+
+func (s *goGitStorage) Blob(file string, revision string) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+	f, err := s.file(file, revision)
+	if err != nil {
+		return "", err
+	}
+	return f.Hash.String(), nil
+}
+
+func (s *goGitStorage) Write(file string, data []byte, message string, author author) error {
+	s.Lock()
+	defer s.Unlock()
+	datapath := dataPath(s.dir, file)
+	err := os.MkdirAll(filepath.Dir(datapath), 0777)
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(datapath, data, 0600)
+	if err != nil {
+		return err
+	}
+
+	w, err := s.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Add(filepath.ToSlash(file)); err != nil {
+		return err
+	}
+	options := &gogit.CommitOptions{}
+	if len(author.Name) > 0 || len(author.Email) > 0 {
+		options.Author = &object.Signature{Name: author.Name, Email: author.Email, When: author.time()}
+	}
+	_, err = w.Commit(message, options)
+	return err
+}
+
+func (s *goGitStorage) Log(file string) ([]pageRevision, error) {
+	s.Lock()
+	defer s.Unlock()
+	var revisions []pageRevision
+	file = filepath.ToSlash(file)
+	commits, err := s.repo.Log(&gogit.LogOptions{FileName: &file, Order: gogit.LogOrderCommitterTime})
+	if err != nil {
+		if err == plumbing.ErrReferenceNotFound {
+			return revisions, nil
+		}
+		return revisions, err
+	}
+	now := time.Now()
+	err = commits.ForEach(func(c *object.Commit) error {
+		revisions = append(revisions, pageRevision{
+			Title:       title(file),
+			Object:      c.Hash.String()[:7],
+			Description: strings.SplitN(c.Message, "\n", 2)[0],
+			Author:      author{Name: c.Author.Name, Email: c.Author.Email},
+			Timestamp:   relativeTime(c.Author.When, now),
+		})
+		return nil
+	})
+	return revisions, err
+}
+
+func (s *goGitStorage) Files(revision string) ([]string, error) {
+	s.Lock()
+	defer s.Unlock()
+	tree, err := s.tree(revision)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	err = tree.Files().ForEach(func(f *object.File) error {
+		files = append(files, f.Name)
+		return nil
+	})
+	return files, err
+}
+
+func (s *goGitStorage) Grep(keyword string) ([]searchResult, error) {
+	s.Lock()
+	defer s.Unlock()
+	results := make([]searchResult, 0)
+	re := grepPattern(keyword)
+	tree, err := s.tree("HEAD")
+	if err == plumbing.ErrReferenceNotFound {
+		return results, nil
+	} else if err != nil {
+		return results, err
+	}
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if !strings.HasSuffix(f.Name, "."+conf.FileExtension) {
+			return nil
+		}
+		if binary, err := f.IsBinary(); err != nil || binary {
+			return err
+		}
+		content, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		results = append(results, grepLines(re, f.Name, []byte(content))...)
+		return nil
+	})
+	return results, err
+}
+
+func (s *goGitStorage) Head() (string, error) {
+	s.Lock()
+	defer s.Unlock()
+	ref, err := s.repo.Head()
+	if err == plumbing.ErrReferenceNotFound {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	head := ref.Hash().String()
+	if head != s.head {
+		// HEAD moved, possibly by another process.
+		s.head = head
+		if err = s.reopen(); err != nil {
+			return "", err
+		}
+	}
+	return head, nil
+}
+
+// relativeTime describes t relative to now the way git log does with
+// --date=relative.
+func relativeTime(t time.Time, now time.Time) string {
+	seconds := int64(now.Sub(t).Seconds() + 0.5)
+	plural := func(n int64, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s ago", n, unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+	switch {
+	case seconds < 0:
+		return "in the future"
+	case seconds < 90:
+		return plural(seconds, "second")
+	case seconds < 90*60:
+		return plural((seconds+30)/60, "minute")
+	case seconds < 36*60*60:
+		return plural((seconds+30*60)/(60*60), "hour")
+	}
+	days := (seconds + 12*60*60) / (24 * 60 * 60)
+	switch {
+	case days < 14:
+		return plural(days, "day")
+	case days < 70:
+		return plural((days+3)/7, "week")
+	case days < 365:
+		return plural((days+15)/30, "month")
+	}
+	return plural((days+183)/365, "year")
+}
+
+// treeFile is a file in a flattened tree.
+type treeFile struct {
+	hash plumbing.Hash
+	mode filemode.FileMode
+}
+
+// treeFiles flattens tree into its files by path.
+func treeFiles(tree *object.Tree) (map[string]treeFile, error) {
+	files := make(map[string]treeFile)
+	err := tree.Files().ForEach(func(f *object.File) error {
+		files[f.Name] = treeFile{hash: f.Hash, mode: f.Mode}
+		return nil
+	})
+	return files, err
+}
+
+func (s *goGitStorage) storeObject(o interface {
+	Encode(plumbing.EncodedObject) error
+}) (plumbing.Hash, error) {
+	obj := s.repo.Storer.NewEncodedObject()
+	if err := o.Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return s.repo.Storer.SetEncodedObject(obj)
+}
+
+func (s *goGitStorage) storeBlob(data []byte) (plumbing.Hash, error) {
+	obj := s.repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err = w.Write(data); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if err = w.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return s.repo.Storer.SetEncodedObject(obj)
+}
+
+// storeTree writes the trees holding files and returns the root.
+func (s *goGitStorage) storeTree(files map[string]treeFile) (plumbing.Hash, error) {
+	tree := &object.Tree{}
+	dirs := make(map[string]map[string]treeFile)
+	for name, f := range files {
+		i := strings.Index(name, "/")
+		if i < 0 {
+			tree.Entries = append(tree.Entries, object.TreeEntry{Name: name, Mode: f.mode, Hash: f.hash})
+			continue
+		}
+		if dirs[name[:i]] == nil {
+			dirs[name[:i]] = make(map[string]treeFile)
+		}
+		dirs[name[:i]][name[i+1:]] = f
+	}
+	for dir, files := range dirs {
+		hash, err := s.storeTree(files)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		tree.Entries = append(tree.Entries, object.TreeEntry{Name: dir, Mode: filemode.Dir, Hash: hash})
+	}
+	// Git sorts directories as if their names ended in a slash.
+	sortName := func(e object.TreeEntry) string {
+		if e.Mode == filemode.Dir {
+			return e.Name + "/"
+		}
+		return e.Name
+	}
+	sort.Slice(tree.Entries, func(i, j int) bool {
+		return sortName(tree.Entries[i]) < sortName(tree.Entries[j])
+	})
+	return s.storeObject(tree)
+}
+
+// signature signs a commit by author, or by the user configured for Git if
+// there is no author.
+func (s *goGitStorage) signature(author author) object.Signature {
+	if len(author.Name) == 0 && len(author.Email) == 0 {
+		if cfg, err := s.repo.ConfigScoped(gitconfig.GlobalScope); err == nil {
+			author.Name, author.Email = cfg.User.Name, cfg.User.Email
+		}
+	}
+	return object.Signature{Name: author.Name, Email: author.Email, When: author.time()}
+}
+
+func (s *goGitStorage) storeCommit(tree plumbing.Hash, message string, author author, parents ...plumbing.Hash) (plumbing.Hash, error) {
+	signature := s.signature(author)
+	return s.storeObject(&object.Commit{
+		Author:       signature,
+		Committer:    signature,
+		Message:      message,
+		TreeHash:     tree,
+		ParentHashes: parents,
+	})
+}
+
+func (s *goGitStorage) commit(revision string) (*object.Commit, error) {
+	hash, err := s.repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.CommitObject(*hash)
+}
+
+func (s *goGitStorage) WriteBranch(branch string, file string, data []byte, message string, author author) error {
+	s.Lock()
+	defer s.Unlock()
+	name := plumbing.NewBranchReferenceName(branch)
+	parent, err := s.repo.Reference(name, true)
+	if err == plumbing.ErrReferenceNotFound {
+		parent, err = s.repo.Head()
+	}
+	if err != nil {
+		return err
+	}
+	commit, err := s.repo.CommitObject(parent.Hash())
+	if err != nil {
+		return err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+	files, err := treeFiles(tree)
+	if err != nil {
+		return err
+	}
+	blob, err := s.storeBlob(data)
+	if err != nil {
+		return err
+	}
+	files[filepath.ToSlash(file)] = treeFile{hash: blob, mode: filemode.Regular}
+	root, err := s.storeTree(files)
+	if err != nil {
+		return err
+	}
+	hash, err := s.storeCommit(root, message, author, parent.Hash())
+	if err != nil {
+		return err
+	}
+	return s.repo.Storer.SetReference(plumbing.NewHashReference(name, hash))
+}
+
+// mergeBase returns HEAD, the tip of branch and their best common ancestor.
+func (s *goGitStorage) mergeBase(branch string) (head, tip, base *object.Commit, err error) {
+	if head, err = s.commit("HEAD"); err != nil {
+		return
+	}
+	if tip, err = s.commit(plumbing.NewBranchReferenceName(branch).String()); err != nil {
+		return
+	}
+	bases, err := head.MergeBase(tip)
+	if err != nil {
+		return
+	}
+	if len(bases) == 0 {
+		err = fmt.Errorf("%s has no history in common with HEAD", branch)
+		return
+	}
+	return head, tip, bases[0], nil
+}
+
+func (s *goGitStorage) Diff(branch string) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+	_, tip, base, err := s.mergeBase(branch)
+	if err != nil {
+		return "", err
+	}
+	patch, err := base.Patch(tip)
+	if err != nil {
+		return "", err
+	}
+	return patch.String(), nil
+}
+
+// Merge merges file by file: a file changed on branch only is taken from
+// branch, and a file changed on both sides is merged line by line like git
+// merge does. Changes to the same lines are a conflict.
+func (s *goGitStorage) Merge(branch string, message string, author author) error {
+	s.Lock()
+	defer s.Unlock()
+	head, tip, base, err := s.mergeBase(branch)
+	if err != nil {
+		return err
+	}
+	if base.Hash == tip.Hash {
+		// Everything on branch is merged already.
+		return nil
+	}
+
+	var baseFiles, headFiles, tipFiles map[string]treeFile
+	for _, c := range []struct {
+		commit *object.Commit
+		files  *map[string]treeFile
+	}{{base, &baseFiles}, {head, &headFiles}, {tip, &tipFiles}} {
+		tree, err := c.commit.Tree()
+		if err != nil {
+			return err
+		}
+		if *c.files, err = treeFiles(tree); err != nil {
+			return err
+		}
+	}
+
+	names := make(map[string]bool)
+	for name := range baseFiles {
+		names[name] = true
+	}
+	for name := range tipFiles {
+		names[name] = true
+	}
+	var conflicts []string
+	for name := range names {
+		b, inBase := baseFiles[name]
+		t, inTip := tipFiles[name]
+		h, inHead := headFiles[name]
+		switch {
+		case inBase == inTip && b == t:
+			// Unchanged on branch.
+		case inHead == inBase && h == b:
+			if inTip {
+				headFiles[name] = t
+			} else {
+				delete(headFiles, name)
+			}
+		case inHead == inTip && h == t:
+			// Changed the same way on both sides.
+		case inBase && inHead && inTip && h.mode == t.mode:
+			hash, ok, err := s.mergeBlobs(b.hash, h.hash, t.hash)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				conflicts = append(conflicts, name)
+				continue
+			}
+			headFiles[name] = treeFile{hash: hash, mode: h.mode}
+		default:
+			conflicts = append(conflicts, name)
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return &mergeConflict{Files: conflicts}
+	}
+
+	root, err := s.storeTree(headFiles)
+	if err != nil {
+		return err
+	}
+	hash, err := s.storeCommit(root, message, author, head.Hash, tip.Hash)
+	if err != nil {
+		return err
+	}
+	w, err := s.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	return w.Reset(&gogit.ResetOptions{Commit: hash, Mode: gogit.HardReset})
+}
+
+func (s *goGitStorage) readBlob(hash plumbing.Hash) ([]byte, error) {
+	blob, err := s.repo.BlobObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	r, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// mergeBlobs merges the changes from base to ours and to theirs, returning
+// the merged blob, or false if they conflict. Binary files always conflict.
+func (s *goGitStorage) mergeBlobs(base, ours, theirs plumbing.Hash) (plumbing.Hash, bool, error) {
+	var contents [3][]byte
+	for i, hash := range []plumbing.Hash{base, ours, theirs} {
+		content, err := s.readBlob(hash)
+		if err != nil {
+			return plumbing.ZeroHash, false, err
+		}
+		if bytes.IndexByte(content, 0) >= 0 {
+			return plumbing.ZeroHash, false, nil
+		}
+		contents[i] = content
+	}
+	merged, ok := mergeLines(string(contents[0]), string(contents[1]), string(contents[2]))
+	if !ok {
+		return plumbing.ZeroHash, false, nil
+	}
+	hash, err := s.storeBlob([]byte(merged))
+	return hash, err == nil, err
+}
+
+// mergeLines is a three-way merge of the lines of ours and theirs, which
+// both changed base. Stretches of lines that changed on one side only are
+// taken from that side; stretches that changed differently on both sides,
+// or touch each other, are a conflict.
+func mergeLines(base, ours, theirs string) (string, bool) {
+	b, o, t := strings.SplitAfter(base, "\n"), strings.SplitAfter(ours, "\n"), strings.SplitAfter(theirs, "\n")
+	inOurs, inTheirs := matchLines(b, o), matchLines(b, t)
+
+	var merged []string
+	i, j, k := 0, 0, 0
+	for i < len(b) || j < len(o) || k < len(t) {
+		if i < len(b) && inOurs[i] == j && inTheirs[i] == k {
+			// Unchanged on both sides.
+			merged = append(merged, b[i])
+			i, j, k = i+1, j+1, k+1
+			continue
+		}
+		// Find the next line that is unchanged on both sides.
+		next, nextOurs, nextTheirs := len(b), len(o), len(t)
+		for n := i; n < len(b); n++ {
+			if inOurs[n] >= j && inTheirs[n] >= k {
+				next, nextOurs, nextTheirs = n, inOurs[n], inTheirs[n]
+				break
+			}
+		}
+		was, mine, other := b[i:next], o[j:nextOurs], t[k:nextTheirs]
+		switch {
+		case equalLines(was, mine):
+			merged = append(merged, other...)
+		case equalLines(was, other), equalLines(mine, other):
+			merged = append(merged, mine...)
+		default:
+			return "", false
+		}
+		i, j, k = next, nextOurs, nextTheirs
+	}
+	return strings.Join(merged, ""), true
+}
+
+// matchLines matches the lines of a to those of b along a Myers diff, which
+// takes linear space, returning the index in b of each line of a, or -1.
+func matchLines(a, b []string) []int {
+	codes := map[string]rune{}
+	encode := func(lines []string) []rune {
+		runes := make([]rune, len(lines))
+		for i, line := range lines {
+			r, ok := codes[line]
+			if !ok {
+				// Lines are numbered past the surrogates, which are no runes.
+				r = rune(len(codes)) + 1
+				if r >= 0xD800 {
+					r += 0x800
+				}
+				codes[line] = r
+			}
+			runes[i] = r
+		}
+		return runes
+	}
+
+	matches := make([]int, len(a))
+	i, j := 0, 0
+	for _, d := range diffmatchpatch.New().DiffMainRunes(encode(a), encode(b), false) {
+		n := utf8.RuneCountInString(d.Text)
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			for ; n > 0; n-- {
+				matches[i] = j
+				i, j = i+1, j+1
+			}
+		case diffmatchpatch.DiffDelete:
+			for ; n > 0; n-- {
+				matches[i] = -1
+				i++
+			}
+		case diffmatchpatch.DiffInsert:
+			j += n
+		}
+	}
+	return matches
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *goGitStorage) DeleteBranch(branch string) error {
+	s.Lock()
+	defer s.Unlock()
+	return s.repo.Storer.RemoveReference(plumbing.NewBranchReferenceName(branch))
+}
@@ -2,23 +2,27 @@ package main
 
 import (
 	// stdlib
+	"bytes"
 	"encoding/base64"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"log"
+	"mime"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"text/template"
+	"time"
 
 	// external
 	"github.com/VictorLowther/go-git/git"
 	auth "github.com/abbot/go-http-auth"
-	"github.com/russross/blackfriday"
+	"golang.org/x/text/unicode/norm"
 )
 
 const (
@@ -34,6 +38,10 @@ var (
 	templates      *template.Template
 	validPath      *regexp.Regexp
 	validLink      *regexp.Regexp
+	gollumLink     *regexp.Regexp
+	codeSpan       *regexp.Regexp
+	listItem       *regexp.Regexp
+	validFile      *regexp.Regexp
 	tableTag       *regexp.Regexp
 	authenticator  *auth.BasicAuth
 )
@@ -41,11 +49,28 @@ var (
 type page struct {
 	SiteName    string
 	Title       string
+	Name        string
 	Theme       string
 	Author      author
 	Body        string
 	Description string
 	Revisions   []pageRevision
+	Draft       string
+	Drafts      bool
+	Lock        *editLock
+	Heartbeat   int
+	// RedirectedFrom is the redirect the reader came from, RedirectTo where
+	// the page redirects to when it is viewed as is.
+	RedirectedFrom string
+	RedirectTo     string
+	// Templates are the template pages a new page can start from, Template
+	// the one it started from.
+	Templates []string
+	Template  string
+	// Autosave is the edit in progress that the user may want back, saved
+	// every AutosaveInterval milliseconds.
+	Autosave         *autosave
+	AutosaveInterval int
 }
 
 type searchPage struct {
@@ -58,38 +83,50 @@ type searchPage struct {
 type historyPage struct {
 	SiteName  string
 	Title     string
+	Name      string
 	Theme     string
 	Revisions []pageRevision
+	NoHistory bool
 }
 
 func (p *page) save() error {
 	filename := fileName(p.Title)
-	datapath := dataPath(conf.DataDir, filename)
 
-	err := os.MkdirAll(filepath.Dir(datapath), 0777)
-	if err != nil {
-		return err
-	}
-	err = ioutil.WriteFile(datapath, []byte(p.Body), 0600)
-	if err != nil {
-		return err
+	message := p.Description
+	if len(message) == 0 {
+		message = fmt.Sprintf("Update %s", filename)
 	}
 
-	_, err = gitAdd(filename)
-	if err != nil {
-		return err
+	repoLock.Lock()
+	defer repoLock.Unlock()
+
+	// Drafts are committed to their own branch and go live when merged.
+	if len(p.Draft) > 0 {
+		drafts, ok := store.(branchStorage)
+		if !ok {
+			return errNoDrafts
+		}
+		err := drafts.WriteBranch(draftBranch(p.Draft), filename, []byte(p.Body), message, p.Author)
+		if err != nil {
+			return err
+		}
+		log.Printf("Committed %s to draft %s: %s\n", filename, p.Draft, message)
+		return nil
 	}
 
-	message := p.Description
-	if len(message) == 0 {
-		message = fmt.Sprintf("Update %s", filename)
+	if syncEnabled() {
+		syncPull()
 	}
-	stdout, err := gitCommit(message, p.Author)
+	err := store.Write(filename, []byte(p.Body), message, p.Author)
 	if err != nil {
 		return err
 	}
-	log.Println(stdout)
+	log.Printf("Committed %s: %s\n", filename, message)
 
+	if syncEnabled() {
+		syncPush()
+	}
+	checkHead()
 	return nil
 }
 
@@ -106,35 +143,200 @@ func loadPage(title string, revision string) (*page, error) {
 	if len(revision) == 0 {
 		revision = "HEAD"
 	}
-	body, err := gitShow(filename, revision)
+	body, err := store.Read(filename, revision)
+	if err != nil {
+		// Titles are matched regardless of case as a last resort, once.
+		if files, e := store.Files(revision); e == nil {
+			if found, ok := findPage(title, files); ok {
+				if body, err = store.Read(fileName(found), revision); err == nil {
+					title = found
+				}
+			}
+		}
+	}
 	if err != nil {
 		return &page{
 			Title:    title,
 			Theme:    conf.Theme,
-			Body:     body.String(),
+			Body:     string(body),
 			SiteName: conf.Name,
 		}, fmt.Errorf("Unable to load page content from %s at %s\n", filename, revision)
 	}
-	return &page{Title: title, Theme: conf.Theme, Body: body.String(), SiteName: conf.Name}, nil
+	return &page{Title: title, Name: pageTitle(title, body), Theme: conf.Theme, Body: string(body), SiteName: conf.Name}, nil
 }
 
+// processLinks turns [Title]() into a link to the page named after Title.
+// Links in code are left alone.
 func processLinks(content []byte, link *regexp.Regexp) []byte {
+	return outsideCode(content, func(text []byte) []byte {
+		return link.ReplaceAllFunc(text, func(match []byte) []byte {
+			text := string(link.FindSubmatch(match)[1])
+			return []byte(fmt.Sprintf("[%s](%s)", text, pageURL(slug(text))))
+		})
+	})
+}
+
+// outsideCode applies process to the parts of content that are not code, so
+// that fenced and indented code blocks and code spans are left alone.
+func outsideCode(content []byte, process func([]byte) []byte) []byte {
+	lines := strings.Split(string(content), "\n")
+	code := codeLines(lines)
+	var out, text []string
+	flush := func() {
+		if len(text) > 0 {
+			out = append(out, outsideCodeSpans(strings.Join(text, "\n"), process))
+			text = nil
+		}
+	}
+	for i, line := range lines {
+		if code[i] {
+			flush()
+			out = append(out, line)
+		} else {
+			text = append(text, line)
+		}
+	}
+	flush()
+	return []byte(strings.Join(out, "\n"))
+}
+
+// codeLines tells which of lines are part of a code block: fenced by ```
+// or ~~~, or indented by four spaces or a tab after a blank line. Lines
+// indented within a list are part of its items instead.
+func codeLines(lines []string) []bool {
+	code := make([]bool, len(lines))
+	fence := ""
+	list, blank, indented := false, true, false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case len(fence) > 0:
+			code[i] = true
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```"), strings.HasPrefix(trimmed, "~~~"):
+			code[i] = true
+			fence = trimmed[:3]
+			indented = false
+		case len(trimmed) == 0:
+			code[i] = indented
+		case strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t"):
+			indented = indented || (blank && !list)
+			code[i] = indented
+		default:
+			indented = false
+			if listItem.MatchString(line) {
+				list = true
+			} else if blank {
+				list = false
+			}
+		}
+		blank = len(trimmed) == 0
+	}
+	return code
+}
+
+func outsideCodeSpans(text string, process func([]byte) []byte) string {
+	var out bytes.Buffer
+	last := 0
+	for _, m := range codeSpan.FindAllStringIndex(text, -1) {
+		out.Write(process([]byte(text[last:m[0]])))
+		out.WriteString(text[m[0]:m[1]])
+		last = m[1]
+	}
+	out.Write(process([]byte(text[last:])))
+	return out.String()
+}
+
+// processGollumLinks rewrites the links of Gollum and GitHub wikis:
+// [[Page]] and [[Link text|Page]] link to a page, named after the title as
+// by processLinks, and [[image.png]] embeds an attached image, which may be
+// followed by options such as |alt=Text. Links in code are left alone.
+func processGollumLinks(content []byte, link *regexp.Regexp) []byte {
+	return outsideCode(content, func(text []byte) []byte {
+		return gollumLinks(text, link)
+	})
+}
+
+func gollumLinks(content []byte, link *regexp.Regexp) []byte {
 	return link.ReplaceAllFunc(content, func(match []byte) []byte {
-		return link.ReplaceAll(match, []byte("[$1]($1)"))
+		m := link.FindSubmatch(match)
+		text, target := strings.TrimSpace(string(m[1])), strings.TrimSpace(string(m[2]))
+
+		if isImage(text) {
+			alt := path.Base(text)
+			for _, option := range strings.Split(target, "|") {
+				if strings.HasPrefix(option, "alt=") {
+					alt = strings.TrimPrefix(option, "alt=")
+				}
+			}
+			return []byte(fmt.Sprintf("![%s](%s)", alt, gollumTarget(text)))
+		}
+		if len(target) == 0 {
+			target = text
+		}
+		return []byte(fmt.Sprintf("[%s](%s)", text, gollumTarget(target)))
 	})
 }
 
+// gollumTarget is where a Gollum link to target goes. Pages are named after
+// their title, attachments keep their name, and absolute links are taken
+// from the top of the wiki.
+func gollumTarget(target string) string {
+	fragment := ""
+	if i := strings.Index(target, "#"); i >= 0 {
+		target, fragment = target[:i], target[i:]
+	}
+	absolute := strings.HasPrefix(target, "/")
+	if !validFile.MatchString("/view/" + strings.TrimPrefix(target, "/")) {
+		target = slug(target)
+	}
+	target = pageURL(strings.TrimPrefix(target, "/"))
+	if absolute {
+		target = "/view/" + target
+	}
+	return target + fragment
+}
+
+func isImage(file string) bool {
+	switch strings.ToLower(path.Ext(file)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp", ".bmp":
+		return true
+	}
+	return false
+}
+
 func processTables(content []byte, table *regexp.Regexp) []byte {
 	return table.ReplaceAllFunc(content, func(match []byte) []byte {
 		return table.ReplaceAll(match, []byte(`<table class="`+conf.TableClass+`">`))
 	})
 }
 
+// renderPage renders the markdown content of page at revision to HTML,
+// returning the pages it includes as well. Links to pages for which exists
+// is false are marked as missing; with a nil exists, no links are marked.
+func renderPage(title string, content []byte, revision string, exists func(string) bool) ([]byte, []include) {
+	var deps []include
+	content, tasks := markTasks(content)
+	_, content = frontMatter(content)
+	content = expandIncludes(title, content, revision, "", nil, &deps)
+	content = processGollumLinks(content, gollumLink)
+	content = processLinks(content, validLink)
+	if exists != nil {
+		content = processMissingLinks(content, title, exists)
+	}
+	content = renderMarkdown(content)
+	content = checkTasks(content, tasks)
+	content = processTables(content, tableTag)
+	return content, deps
+}
+
 /*
 TODO: How to combine the three functions? With use of an interface for the page?
 */
 
-func renderTemplate(w http.ResponseWriter, tmpl string, p *page) {
+func renderTemplate(w http.ResponseWriter, tmpl string, p interface{}) {
 	err := templates.ExecuteTemplate(w, tmpl, p)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -157,16 +359,23 @@ func renderSearchTemplate(w http.ResponseWriter, tmpl string, p *searchPage) {
 
 func makeHandler(fn func(http.ResponseWriter, *http.Request, string)) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		path := r.URL.Path
+		path := norm.NFC.String(r.URL.Path)
 		log.Println(path)
 		if path == "/" {
 			path = "/view/"
 		}
 		if path[len(path)-1:len(path)] == "/" {
 			viewIndex := path + conf.IndexPage
+			if len(r.URL.RawQuery) > 0 {
+				viewIndex += "?" + r.URL.RawQuery
+			}
 			http.Redirect(w, r, viewIndex, http.StatusFound)
 			return
 		}
+		if m := validFile.FindStringSubmatch(path); m != nil {
+			fileHandler(w, r, m[1])
+			return
+		}
 		m := validPath.FindStringSubmatch(path)
 		if m == nil {
 			http.NotFound(w, r)
@@ -178,7 +387,7 @@ func makeHandler(fn func(http.ResponseWriter, *http.Request, string)) http.Handl
 
 func makeAuthHandler(fn func(http.ResponseWriter, *auth.AuthenticatedRequest, string)) auth.AuthenticatedHandlerFunc {
 	return func(w http.ResponseWriter, r *auth.AuthenticatedRequest) {
-		path := r.URL.Path
+		path := norm.NFC.String(r.URL.Path)
 		log.Println(path)
 		m := validPath.FindStringSubmatch(path)
 		if m == nil {
@@ -190,35 +399,156 @@ func makeAuthHandler(fn func(http.ResponseWriter, *auth.AuthenticatedRequest, st
 }
 
 func viewHandler(w http.ResponseWriter, r *http.Request, title string) {
+	draft := previewDraft(w, r)
 	revision := r.FormValue("revision")
 	if revision == "" {
 		revision = "HEAD"
+		if len(draft) > 0 {
+			revision = draftBranch(draft)
+		}
 	}
 
 	p, err := loadPage(title, revision)
 	if err != nil {
-		http.Redirect(w, r, "/edit/"+title, http.StatusFound)
+		createPage(w, r, title)
+		return
+	}
+	// Found regardless of case.
+	if p.Title != title {
+		target := "/view/" + pageURL(p.Title)
+		if len(r.URL.RawQuery) > 0 {
+			target += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, target, http.StatusFound)
 		return
 	}
 
-	content := []byte(p.Body)
-	content = processLinks(content, validLink)
-	content = blackfriday.MarkdownCommon(content)
-	content = processTables(content, tableTag)
-	p.Body = string(content)
+	// Redirects are followed once, unless the redirect is to be viewed.
+	if from := r.FormValue("redirectedfrom"); validPath.MatchString("/view/" + from) {
+		p.RedirectedFrom = from
+	}
+	if target, fragment, ok := redirectTarget(title, []byte(p.Body)); ok {
+		if r.FormValue("redirect") != "no" && len(p.RedirectedFrom) == 0 {
+			http.Redirect(w, r, "/view/"+pageURL(target)+"?redirectedfrom="+url.QueryEscape(title)+fragment, http.StatusFound)
+			return
+		}
+		p.RedirectTo = target
+	}
+
+	// Pages are rendered from their blob, so a cached rendering of the blob
+	// on the same page can be used as is.
+	// The pages it includes are checked as well.
+	var blob, body string
+	var cached bool
+	if rendered != nil {
+		if blob, err = store.Blob(fileName(title), revision); err == nil {
+			body, cached = rendered.get(renderKey(title, blob) + includeKey(includes.get(title), revision))
+		}
+	}
+	if !cached {
+		rendering, deps := renderPage(title, []byte(p.Body), revision, wikiPages.exists)
+		body = string(rendering)
+		includes.set(title, deps)
+		if len(blob) > 0 {
+			rendered.put(renderKey(title, blob)+includeKey(deps, revision), body)
+		}
+	}
+	p.Body = body
+	p.Draft = draft
 
 	renderTemplate(w, "view", p)
 }
 
+// createPage sends the reader of a page that does not exist to the editor,
+// passing on the title the page was linked with.
+func createPage(w http.ResponseWriter, r *http.Request, title string) {
+	target := "/edit/" + pageURL(title)
+	if name := r.FormValue("title"); len(name) > 0 {
+		target += "?title=" + url.QueryEscape(name)
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// fileHandler serves a file attached to the wiki, such as an image, as it
+// is. It is sandboxed so that it cannot run scripts in the wiki.
+func fileHandler(w http.ResponseWriter, r *http.Request, file string) {
+	revision := r.FormValue("revision")
+	if revision == "" {
+		revision = "HEAD"
+		if draft := requestDraft(r); len(draft) > 0 {
+			revision = draftBranch(draft)
+		}
+	}
+	if hiddenFile(file) {
+		http.NotFound(w, r)
+		return
+	}
+	content, err := store.Read(file, revision)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if contentType := mime.TypeByExtension(path.Ext(file)); len(contentType) > 0 {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Write(content)
+}
+
 func editHandler(w http.ResponseWriter, r *auth.AuthenticatedRequest, title string) {
+	draft := requestDraft(&r.Request)
 	revision := r.FormValue("revision")
 	if revision == "" {
 		revision = "HEAD"
 	}
 
-	p, err := loadPage(title, revision)
+	// Pages not edited on the draft yet start from HEAD.
+	p, err := loadPage(title, draftBranch(draft))
+	if len(draft) == 0 || len(r.FormValue("revision")) > 0 || err != nil {
+		p, err = loadPage(title, revision)
+	}
 	if err != nil {
-		p = &page{Title: title, Theme: conf.Theme, SiteName: conf.Name}
+		// New pages may start from a template.
+		p = &page{Title: title, Name: defaultTitle(title), Theme: conf.Theme, SiteName: conf.Name,
+			Templates: pageTemplates(), Template: r.FormValue("template")}
+		if name := r.FormValue("title"); slug(name) == path.Base(title) {
+			p.Name = name
+		}
+		var body string
+		if len(p.Template) > 0 {
+			content, err := store.Read(fileName(path.Join(templateNamespace, slug(p.Template))), "HEAD")
+			if err != nil {
+				http.Error(w, "No such template: "+p.Template, http.StatusNotFound)
+				return
+			}
+			body = fillTemplate(content, p.Name, requestAuthor(r).Name, time.Now())
+		}
+		p.Body = withTitle(title, p.Name, body)
+	} else if p.Title != title {
+		// Found regardless of case.
+		http.Redirect(w, &r.Request, "/edit/"+pageURL(p.Title), http.StatusFound)
+		return
+	}
+	p.Draft = draft
+	p.Drafts = draftsEnabled()
+
+	// Offer the edit in progress back, if it was not saved.
+	if autosaves != nil {
+		p.AutosaveInterval = int(conf.Autosave.Interval.Duration / time.Millisecond)
+		if a, ok := autosaves.get(r.Username, title); ok && a.Body != p.Body {
+			p.Autosave = &a
+		}
+	}
+
+	// Tell the editor who else is editing the page, unless taking over.
+	if editLocks != nil {
+		l, ok := editLocks.acquire(title, r.Username, lockName(r), len(r.FormValue("takeover")) > 0)
+		if ok {
+			p.Heartbeat = int(editLocks.expire / time.Millisecond / 3)
+		} else {
+			p.Lock = &l
+		}
 	}
 
 	renderTemplate(w, "edit", p)
@@ -227,29 +557,64 @@ func editHandler(w http.ResponseWriter, r *auth.AuthenticatedRequest, title stri
 func saveHandler(w http.ResponseWriter, r *auth.AuthenticatedRequest, title string) {
 	body := r.FormValue("body")
 	description := r.FormValue("description")
-	user := conf.Auth[r.Username]
-	author := author{Name: user.Name, Email: user.Email}
-	p := &page{Title: title, Theme: conf.Theme, Body: body, Description: description, Author: author}
+	draft := r.FormValue("draft")
+	if len(draft) > 0 && !validDraft.MatchString(draft) {
+		http.Error(w, "Draft names may only contain letters, digits, dashes and underscores", http.StatusBadRequest)
+		return
+	}
+	author := requestAuthor(r)
+	p := &page{Title: title, Theme: conf.Theme, Body: body, Description: description, Author: author, Draft: draft}
 	err := p.save()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	http.Redirect(w, &r.Request, "/view/"+title, http.StatusFound)
+	if editLocks != nil {
+		editLocks.release(title, r.Username)
+	}
+	if autosaves != nil {
+		if err = autosaves.discard(r.Username, title); err != nil {
+			log.Printf("Unable to discard the autosave of %s by %s: %v\n", title, r.Username, err)
+		}
+	}
+	// Preview the draft saved to, or stop previewing after saving live.
+	target := "/view/" + title
+	if _, err = r.Cookie(draftCookie); err == nil || len(draft) > 0 {
+		target += "?draft=" + draft
+	}
+	http.Redirect(w, &r.Request, target, http.StatusFound)
+}
+
+// previewHandler renders the posted body of a page the way viewHandler
+// renders the page, for the editor to show while typing.
+func previewHandler(w http.ResponseWriter, r *auth.AuthenticatedRequest, title string) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	revision := "HEAD"
+	if draft := requestDraft(&r.Request); len(draft) > 0 {
+		revision = draftBranch(draft)
+	}
+	body, _ := renderPage(title, []byte(r.FormValue("body")), revision, wikiPages.exists)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(body)
 }
 
 func historyHandler(w http.ResponseWriter, r *http.Request, title string) {
-	revisions, _ := gitLog(fileName(title))
-	p := &historyPage{Title: title, Theme: conf.Theme, Revisions: revisions, SiteName: conf.Name}
+	revisions, err := store.Log(fileName(title))
+	p := &historyPage{Title: title, Name: wikiPages.title(title), Theme: conf.Theme, Revisions: revisions, SiteName: conf.Name,
+		NoHistory: err == errNoHistory}
 	renderHistoryTemplate(w, "history", p)
 }
 
 func searchHandler(w http.ResponseWriter, r *http.Request) {
 	search := r.FormValue("search")
-	results, err := gitGrep(search)
+	results, err := store.Grep(search)
 	if err != nil {
 		log.Println("error in search", err)
 	}
+	results = append(results, includingResults(results)...)
 	p := &searchPage{Title: "Search", Theme: conf.Theme, Results: results, SiteName: conf.Name}
 	renderSearchTemplate(w, "search", p)
 }
@@ -287,12 +652,38 @@ func init() {
 	loadBundle()
 
 	templateFiles = map[string]string{"header": "_header.html", "footer": "_footer.html", "edit": "edit.html",
-		"history": "history.html", "search": "search.html", "view": "view.html"}
-	validPath = regexp.MustCompile("^/(edit|save|view|history)/([a-zA-Z0-9/_-]+)$")
+		"history": "history.html", "search": "search.html", "view": "view.html", "twofactor": "twofactor.html",
+		"sync": "sync.html", "changes": "changes.html", "change": "change.html"}
+	validPath = regexp.MustCompile(`^/(edit|save|view|history|lock|preview|autosave|task)/([\pL\pM\pN/_-]+)$`)
 	validLink = regexp.MustCompile(`\[([^\]]+)]\(\)`)
+	gollumLink = regexp.MustCompile(`\[\[([^\]|]+)(?:\|([^\]]+))?\]\]`)
+	codeSpan = regexp.MustCompile("``[\\s\\S]*?``|`[^`]*`")
+	listItem = regexp.MustCompile(`^ {0,3}(?:[-*+]|\d+[.)])\s`)
+	validFile = regexp.MustCompile(`^/view/([\pL\pM\pN/_. -]+\.[a-zA-Z][a-zA-Z0-9]*)$`)
 	tableTag = regexp.MustCompile(`<table>`)
 }
 
+// loadTemplates loads the templates. Use the default embedded templates
+// unless a directory of templates is specified in configuration.
+func loadTemplates() {
+	funcs := template.FuncMap{"exported": func() bool { return exporting }, "titleOf": titleOf}
+	if len(conf.TemplateDir) == 0 {
+		templates = template.Must(template.New("bundle").Funcs(funcs).Parse(""))
+		for _, file := range templateFiles {
+			data := _bundle[filepath.Join("templates", file)]
+			template.Must(templates.Parse(string(data)))
+		}
+	} else {
+		templateLocations := make([]string, len(templateFiles))
+		i := 0
+		for _, file := range templateFiles {
+			templateLocations[i] = filepath.Join(conf.TemplateDir, file)
+			i += 1
+		}
+		templates = template.Must(template.New("bundle").Funcs(funcs).ParseFiles(templateLocations...))
+	}
+}
+
 func secret(username, realm string) string {
 	return conf.Auth[username].Password
 }
@@ -302,6 +693,10 @@ func serviceAddress(host string, port int) string {
 }
 
 func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %[1]s [flags]\n       %[1]s [flags] init [dir]\n       %[1]s [flags] export <outdir> [--revision X]\n       %[1]s [flags] epub <file> [prefix] [--revision X]\n       %[1]s [flags] import mediawiki <dump.xml>\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
 	// Display version and exit (flag -version)
@@ -316,6 +711,14 @@ func main() {
 		return
 	}
 
+	// Set up a new wiki and exit (init [dir])
+	if flag.Arg(0) == "init" {
+		if err := initWiki(flag.Arg(1)); err != nil {
+			fmt.Printf("FATAL: Unable to initialize the wiki: %v\n", err)
+		}
+		return
+	}
+
 	// Load the configuration. Use the default embedded configuration unless
 	// a config file was specified at the command line.
 	var err error
@@ -332,31 +735,114 @@ func main() {
 
 	// Load authentication from the config and run the authenticator.
 	conf.loadAuth()
+	if err = conf.loadProxies(); err != nil {
+		fmt.Printf("FATAL: Unable to load trusted proxies: %v\n", err)
+		return
+	}
 	authenticator = auth.NewBasicAuthenticator(serviceAddress(conf.Host, conf.Port), secret)
-
-	// Load the templates. Use the default embedded templates unless a directory
-	// of templates is specified in configuration.
-	if len(conf.TemplateDir) == 0 {
-		templates = template.Must(template.New("bundle").Parse(""))
-		for _, file := range templateFiles {
-			data := _bundle[filepath.Join("templates", file)]
-			template.Must(templates.Parse(string(data)))
+	if conf.LoginThrottle.Enabled {
+		t := conf.LoginThrottle
+		logins = newThrottle(t.Backoff.Duration, t.MaxBackoff.Duration, t.MaxFailures, t.Lockout.Duration)
+	}
+	if conf.TwoFactor.Enabled {
+		if len(conf.TwoFactor.Issuer) == 0 {
+			conf.TwoFactor.Issuer = conf.Name
 		}
-	} else {
-		templateLocations := make([]string, len(templateFiles))
-		i := 0
-		for _, file := range templateFiles {
-			templateLocations[i] = filepath.Join(conf.TemplateDir, file)
-			i += 1
+		twoFactors, err = loadTotpStore(filepath.Join(conf.StateDir, "totp.json"))
+		if err != nil {
+			fmt.Printf("FATAL: Unable to load two-factor enrollments: %v\n", err)
+			return
 		}
-		templates = template.Must(template.ParseFiles(templateLocations...))
 	}
 
+	loadTemplates()
+
 	// Load the repository.
-	if repo, err = git.Open(conf.DataDir); err != nil {
+	if store, err = openStorage(conf.Storage, conf.DataDir); err != nil {
 		log.Fatalf("Unable to open the repo at %v. Please check to make sure it exists and is initialized.\n%v\n", conf.DataDir, err)
 	}
 
+	// Export a static copy of the wiki and exit (export <outdir>)
+	if flag.Arg(0) == "export" {
+		if err = exportCommand(flag.Args()[1:]); err != nil {
+			fmt.Printf("FATAL: Unable to export the wiki: %v\n", err)
+		}
+		return
+	}
+
+	// Export pages as an EPUB book and exit (epub <file> [prefix])
+	if flag.Arg(0) == "epub" {
+		if err = epubCommand(flag.Args()[1:]); err != nil {
+			fmt.Printf("FATAL: Unable to export the book: %v\n", err)
+		}
+		return
+	}
+
+	// Import pages from another wiki and exit (import mediawiki <dump.xml>)
+	if flag.Arg(0) == "import" {
+		if err = importCommand(flag.Args()[1:]); err != nil {
+			fmt.Printf("FATAL: Unable to import the wiki: %v\n", err)
+		}
+		return
+	}
+
+	// Drafts need a storage backend with branches.
+	if _, ok := store.(branchStorage); ok {
+		if changes, err = loadChangeStore(filepath.Join(conf.StateDir, "changes.json")); err != nil {
+			log.Fatalf("Unable to load change requests: %v\n", err)
+		}
+	}
+
+	// Synchronization runs the git executable, whatever the storage backend.
+	if syncEnabled() && repo == nil {
+		if repo, err = git.Open(conf.DataDir); err != nil {
+			log.Fatalf("Unable to open the repo at %v for synchronization. Please check that git is installed.\n%v\n", conf.DataDir, err)
+		}
+	}
+
+	// Warn editors about each other.
+	if conf.EditLock.Enabled {
+		if conf.EditLock.Expire.Duration <= 0 {
+			conf.EditLock.Expire.Duration = 2 * time.Minute
+		}
+		editLocks = newLockTable(conf.EditLock.Expire.Duration)
+	}
+
+	// Keep edits in progress.
+	if conf.Autosave.Enabled {
+		if conf.Autosave.Interval.Duration <= 0 {
+			conf.Autosave.Interval.Duration = 15 * time.Second
+		}
+		if autosaves, err = loadAutosaveStore(filepath.Join(conf.StateDir, "autosave.json")); err != nil {
+			log.Fatalf("Unable to load autosaves: %v\n", err)
+		}
+	}
+
+	// Cache rendered pages, unless disabled.
+	if conf.Cache.MaxEntries > 0 || conf.Cache.MaxBytes > 0 {
+		rendered = newRenderCache(conf.Cache.MaxEntries, conf.Cache.MaxBytes)
+	}
+
+	// Pages are added and removed by commits, wherever they come from.
+	onHeadChange(wikiPages.reset)
+
+	// Keep track of HEAD, so that changes made outside of Goiki are noticed.
+	checkHead()
+	if conf.WatchRepo {
+		if err = watchRepo(conf.DataDir); err != nil {
+			log.Printf("Unable to watch the repo at %v for changes: %v\n", conf.DataDir, err)
+		}
+	}
+
+	// Synchronize with the remote on startup and then on schedule.
+	if syncEnabled() {
+		log.Printf("Synchronizing with %s %s\n", conf.Sync.Remote, conf.Sync.Branch)
+		syncNow()
+		if conf.Sync.Interval.Duration > 0 {
+			go syncSchedule(conf.Sync.Interval.Duration)
+		}
+	}
+
 	// Static routes
 	// If a static directory is provided in the configuration, use that; otherwise
 	// use the embedded static content
@@ -368,13 +854,23 @@ func main() {
 
 	// Unathenticated routes
 	http.HandleFunc("/search/", searchHandler)
-	http.HandleFunc("/", makeHandler(viewHandler))
-	http.HandleFunc("/view/", makeHandler(viewHandler))
-	http.HandleFunc("/history/", makeHandler(historyHandler))
+	http.HandleFunc("/", draftWrap(makeHandler(viewHandler)))
+	http.HandleFunc("/view/", draftWrap(makeHandler(viewHandler)))
+	http.HandleFunc("/history/", draftWrap(makeHandler(historyHandler)))
+	http.HandleFunc("/export/epub/", draftWrap(epubHandler))
+	http.HandleFunc("/hooks/post-receive", hookHandler)
 
 	// Authenticated routes
-	http.HandleFunc("/edit/", authenticator.Wrap(makeAuthHandler(editHandler)))
-	http.HandleFunc("/save/", authenticator.Wrap(makeAuthHandler(saveHandler)))
+	http.HandleFunc("/edit/", authWrap(makeAuthHandler(editHandler)))
+	http.HandleFunc("/save/", authWrap(makeAuthHandler(saveHandler)))
+	http.HandleFunc("/lock/", authWrap(makeAuthHandler(lockHandler)))
+	http.HandleFunc("/preview/", authWrap(makeAuthHandler(previewHandler)))
+	http.HandleFunc("/autosave/", authWrap(makeAuthHandler(autosaveHandler)))
+	http.HandleFunc("/task/", authWrap(makeAuthHandler(taskHandler)))
+	http.HandleFunc("/2fa/", loginWrap(twoFactorHandler))
+	http.HandleFunc("/admin/sync", authWrap(syncHandler))
+	http.HandleFunc("/admin/cache", authWrap(cacheHandler))
+	http.HandleFunc("/changes/", authWrap(changesHandler))
 
 	address := serviceAddress(conf.Host, conf.Port)
 
@@ -1,7 +1,14 @@
 package main
 
 import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+
+	auth "github.com/abbot/go-http-auth"
 )
 
 func TestProcessLinks(t *testing.T) {
@@ -9,12 +16,14 @@ func TestProcessLinks(t *testing.T) {
 		[]byte("Life is like riding a [bicycle]()."),
 		[]byte("[Life]() is like riding a [bicycle]()."),
 		[]byte("To keep your [balance]() you must [keep]() your [balance]()."),
+		[]byte("Write `[bicycle]()` to link to the [bicycle]():\n\n    [bicycle]()"),
 	}
 
 	results := [][]byte{
 		[]byte("Life is like riding a [bicycle](bicycle)."),
 		[]byte("[Life](Life) is like riding a [bicycle](bicycle)."),
 		[]byte("To keep your [balance](balance) you must [keep](keep) your [balance](balance)."),
+		[]byte("Write `[bicycle]()` to link to the [bicycle](bicycle):\n\n    [bicycle]()"),
 	}
 
 	for i := 0; i < len(originals); i++ {
@@ -34,7 +43,7 @@ func TestProcessLinksWithSubdirectories(t *testing.T) {
 
 	results := [][]byte{
 		[]byte("Life is like riding a [vehicles/bicycle](vehicles/bicycle)."),
-		[]byte("[Life](Life) is like riding a [transportation vehicles/bicycle](transportation vehicles/bicycle)."),
+		[]byte("[Life](Life) is like riding a [transportation vehicles/bicycle](transportation-vehicles/bicycle)."),
 		[]byte("To keep your [life/balance](life/balance) you must [keep](keep) your [life/goals/balance](life/goals/balance)."),
 	}
 
@@ -45,3 +54,95 @@ func TestProcessLinksWithSubdirectories(t *testing.T) {
 		}
 	}
 }
+
+func TestProcessGollumLinks(t *testing.T) {
+	originals := [][]byte{
+		[]byte("Life is like riding a [[bicycle]]."),
+		[]byte("Keep your [[balance|Staying Upright]] and [[goals|life/Long Term Goals#Health]]."),
+		[]byte("[[wheel.png]] and [[life/front wheel.jpg|alt=Front wheel]]"),
+		[]byte("See the [[manual|Bicycle Manual.pdf]] or [[/Home]]."),
+		[]byte("Check [[Bash]]:\n\n```\nif [[ -f \"$f\" ]]; then\n```\n\n    [[Indented]]\n\nor `[[ -d /tmp ]]`."),
+	}
+
+	results := [][]byte{
+		[]byte("Life is like riding a [bicycle](bicycle)."),
+		[]byte("Keep your [balance](Staying-Upright) and [goals](life/Long-Term-Goals#Health)."),
+		[]byte("![wheel.png](wheel.png) and ![Front wheel](life/front%20wheel.jpg)"),
+		[]byte("See the [manual](Bicycle%20Manual.pdf) or [/Home](/view/Home)."),
+		[]byte("Check [Bash](Bash):\n\n```\nif [[ -f \"$f\" ]]; then\n```\n\n    [[Indented]]\n\nor `[[ -d /tmp ]]`."),
+	}
+
+	for i := 0; i < len(originals); i++ {
+		processed := processGollumLinks(originals[i], gollumLink)
+		if string(processed) != string(results[i]) {
+			t.Errorf("Expected >%s<, got >%s<\n", results[i], processed)
+		}
+	}
+}
+
+func TestOutsideCode(t *testing.T) {
+	upper := func(text []byte) []byte { return bytes.ToUpper(text) }
+	tests := map[string]string{
+		"a `b` c":                      "A `b` C",
+		"a\n```\nb\n```\nc":            "A\n```\nb\n```\nC",
+		"a\n~~~ go\nb\n```\nc\n~~~\nd": "A\n~~~ go\nb\n```\nc\n~~~\nD",
+		"a\n\n    b\n\n    c\nd":       "A\n\n    b\n\n    c\nD",
+		"- a\n\n    b\n- c":            "- A\n\n    B\n- C",
+		"a\n    b":                     "A\n    B",
+	}
+	for content, expected := range tests {
+		if processed := string(outsideCode([]byte(content), upper)); processed != expected {
+			t.Errorf("%q should equal >%s<, but is >%s<", content, expected, processed)
+		}
+	}
+}
+
+func TestProcessLinksWithTitles(t *testing.T) {
+	originals := [][]byte{
+		[]byte("Order from the [Café Menu]()."),
+		[]byte("Read the [Q&A]() and [[FAQ|Frequently Asked Questions?]]."),
+	}
+
+	results := [][]byte{
+		[]byte("Order from the [Café Menu](Caf%C3%A9-Menu)."),
+		[]byte("Read the [Q&A](Q-A) and [FAQ](Frequently-Asked-Questions)."),
+	}
+
+	for i := 0; i < len(originals); i++ {
+		processed := processLinks(processGollumLinks(originals[i], gollumLink), validLink)
+		if string(processed) != string(results[i]) {
+			t.Errorf("Expected >%s<, got >%s<\n", results[i], processed)
+		}
+	}
+}
+
+func TestPreviewRendersLikeView(t *testing.T) {
+	dir := initRepo()
+	defer discardRepo(dir)
+	conf = config{Name: "Goiki", FileExtension: "md", IndexPage: "home", Theme: "default"}
+	defer func() { conf = config{} }()
+	defer wikiPages.reset()
+	loadTemplates()
+
+	content := "---\ntitle: Deploy & Roll Back\n---\nCall {{include:shared/oncall}} or see [Missing]() and [[Runbook]]."
+	a := author{Name: "Test", Email: "test@example.com"}
+	store.Write("shared/oncall.md", []byte("the *on-call* engineer"), "Add oncall", a)
+	store.Write("ops/deploy.md", []byte(content), "Add deploy", a)
+	wikiPages.reset()
+
+	view := httptest.NewRecorder()
+	viewHandler(view, httptest.NewRequest("GET", "/view/ops/deploy", nil), "ops/deploy")
+
+	r := httptest.NewRequest("POST", "/preview/ops/deploy", strings.NewReader(url.Values{"body": {content}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	preview := httptest.NewRecorder()
+	previewHandler(preview, &auth.AuthenticatedRequest{Request: *r, Username: "goiki"}, "ops/deploy")
+
+	body := preview.Body.String()
+	if preview.Code != http.StatusOK || !strings.Contains(body, "<em>on-call</em>") || !strings.Contains(body, `class="missing text-danger"`) {
+		t.Errorf("Preview should render the include and the missing link, but is >%s<", body)
+	}
+	if !strings.Contains(view.Body.String(), "<div>"+body+"</div>") {
+		t.Errorf("Preview should equal the view >%s<, but is >%s<", view.Body.String(), body)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/russross/blackfriday"
+)
+
+// The settings of blackfriday.MarkdownCommon.
+const (
+	markdownFlags = blackfriday.HTML_USE_XHTML |
+		blackfriday.HTML_USE_SMARTYPANTS |
+		blackfriday.HTML_SMARTYPANTS_FRACTIONS |
+		blackfriday.HTML_SMARTYPANTS_DASHES |
+		blackfriday.HTML_SMARTYPANTS_LATEX_DASHES
+	markdownExtensions = blackfriday.EXTENSION_NO_INTRA_EMPHASIS |
+		blackfriday.EXTENSION_TABLES |
+		blackfriday.EXTENSION_FENCED_CODE |
+		blackfriday.EXTENSION_AUTOLINK |
+		blackfriday.EXTENSION_STRIKETHROUGH |
+		blackfriday.EXTENSION_SPACE_HEADERS |
+		blackfriday.EXTENSION_HEADER_IDS |
+		blackfriday.EXTENSION_BACKSLASH_LINE_BREAK |
+		blackfriday.EXTENSION_DEFINITION_LISTS
+)
+
+// darkThemes are the Bootswatch themes with a dark background.
+var darkThemes = map[string]bool{"cyborg": true, "darkly": true, "slate": true, "superhero": true}
+
+// renderMarkdown renders markdown like blackfriday.MarkdownCommon, but with
+// fenced code blocks highlighted.
+func renderMarkdown(content []byte) []byte {
+	renderer := highlightRenderer{blackfriday.HtmlRenderer(markdownFlags, "", "")}
+	return blackfriday.MarkdownOptions(content, renderer, blackfriday.Options{Extensions: markdownExtensions})
+}
+
+// highlightStyle returns the name of the Chroma style to highlight code with,
+// matching the theme unless configured.
+func highlightStyle() string {
+	if len(conf.Highlight.Style) > 0 {
+		return conf.Highlight.Style
+	}
+	if darkThemes[conf.Theme] {
+		return "monokai"
+	}
+	return "github"
+}
+
+// highlightRenderer highlights fenced code blocks in a language Chroma knows,
+// and leaves everything else to the HTML renderer.
+type highlightRenderer struct {
+	blackfriday.Renderer
+}
+
+// BlockCode renders a code block. The language may be followed by options:
+//
+//	```go linenos hl=2,5-7
+//
+// linenos and nolinenos number the lines or not, whatever the configuration;
+// hl highlights lines and ranges of lines.
+func (r highlightRenderer) BlockCode(out *bytes.Buffer, text []byte, info string) {
+	fields := strings.Fields(info)
+	if len(fields) == 0 {
+		r.Renderer.BlockCode(out, text, info)
+		return
+	}
+	lexer := lexers.Get(fields[0])
+	if lexer == nil {
+		r.Renderer.BlockCode(out, text, info)
+		return
+	}
+
+	numbers := conf.Highlight.LineNumbers
+	var lines [][2]int
+	for _, option := range fields[1:] {
+		switch {
+		case option == "linenos":
+			numbers = true
+		case option == "nolinenos":
+			numbers = false
+		case strings.HasPrefix(option, "hl="):
+			lines = lineRanges(strings.TrimPrefix(option, "hl="))
+		}
+	}
+
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, string(text))
+	if err != nil {
+		r.Renderer.BlockCode(out, text, info)
+		return
+	}
+	var code bytes.Buffer
+	formatter := chromahtml.New(chromahtml.WithLineNumbers(numbers), chromahtml.HighlightLines(lines))
+	if err = formatter.Format(&code, styles.Get(highlightStyle()), iterator); err != nil {
+		r.Renderer.BlockCode(out, text, info)
+		return
+	}
+	if out.Len() > 0 {
+		out.WriteByte('\n')
+	}
+	out.Write(code.Bytes())
+	out.WriteByte('\n')
+}
+
+// lineRanges parses lines and ranges of lines such as 2,5-7, skipping what is
+// neither.
+func lineRanges(s string) [][2]int {
+	var ranges [][2]int
+	for _, part := range strings.Split(s, ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		from, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			continue
+		}
+		to := from
+		if len(bounds) == 2 {
+			if to, err = strconv.Atoi(bounds[1]); err != nil || to < from {
+				continue
+			}
+		}
+		ranges = append(ranges, [2]int{from, to})
+	}
+	return ranges
+}
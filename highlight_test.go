@@ -0,0 +1,66 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdownHighlightsCode(t *testing.T) {
+	defer func() { conf = config{} }()
+
+	plain := string(renderMarkdown([]byte("```\necho hi\n```\n")))
+	if plain != "<pre><code>echo hi\n</code></pre>\n" {
+		t.Errorf("Code without a language should equal >%s<, but is >%s<", "<pre><code>echo hi\n</code></pre>\n", plain)
+	}
+	unknown := string(renderMarkdown([]byte("```klingon\nQapla'\n```\n")))
+	if !strings.Contains(unknown, `<code class="language-klingon">`) {
+		t.Errorf("Code in an unknown language should be left alone, but is >%s<", unknown)
+	}
+
+	code := "```go hl=2\npackage main\nfunc main() {}\n```\n"
+	highlighted := string(renderMarkdown([]byte(code)))
+	if !strings.Contains(highlighted, `<pre style="`) || !strings.Contains(highlighted, "package</span>") {
+		t.Errorf("Go code should be highlighted, but is >%s<", highlighted)
+	}
+	if strings.Count(highlighted, "background-color") != 2 {
+		t.Errorf("Second line should be highlighted, but is >%s<", highlighted)
+	}
+	if strings.Contains(highlighted, ">1</span>") {
+		t.Errorf("Lines should not be numbered, but are >%s<", highlighted)
+	}
+
+	conf.Highlight.LineNumbers = true
+	numbered := string(renderMarkdown([]byte(code)))
+	if !strings.Contains(numbered, ">1</span>") || !strings.Contains(numbered, ">2</span>") {
+		t.Errorf("Lines should be numbered, but are >%s<", numbered)
+	}
+	unnumbered := string(renderMarkdown([]byte("```go nolinenos\npackage main\n```\n")))
+	if strings.Contains(unnumbered, ">1</span>") {
+		t.Errorf("Lines should not be numbered with nolinenos, but are >%s<", unnumbered)
+	}
+}
+
+func TestHighlightStyle(t *testing.T) {
+	defer func() { conf = config{} }()
+
+	tests := map[string]string{"default": "github", "flatly": "github", "darkly": "monokai", "slate": "monokai"}
+	for theme, expected := range tests {
+		conf.Theme = theme
+		if style := highlightStyle(); style != expected {
+			t.Errorf("Style for %s should equal >%s<, but is >%s<", theme, expected, style)
+		}
+	}
+	conf.Highlight.Style = "dracula"
+	if style := highlightStyle(); style != "dracula" {
+		t.Errorf("Style should equal >dracula<, but is >%s<", style)
+	}
+}
+
+func TestLineRanges(t *testing.T) {
+	ranges := lineRanges("2,5-7,x,9-8")
+	expected := [][2]int{{2, 2}, {5, 7}}
+	if !reflect.DeepEqual(ranges, expected) {
+		t.Errorf("Line ranges should equal >%v<, but are >%v<", expected, ranges)
+	}
+}
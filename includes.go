@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"sync"
+)
+
+// maxIncludeDepth is how deeply includes may be nested.
+const maxIncludeDepth = 5
+
+var (
+	includes         = &includeTable{pages: make(map[string][]include)}
+	includeDirective = regexp.MustCompile(`\{\{include:\s*([^}@]+?)\s*(?:@([^}\s]+))?\s*\}\}`)
+)
+
+// include is a page included in another, at a pinned revision or at the
+// revision of the including page if Revision is empty.
+type include struct {
+	Page     string
+	Revision string
+}
+
+// includeTable records the pages that each page included when it was last
+// rendered, so that a cached rendering can be checked against them.
+type includeTable struct {
+	sync.Mutex
+	pages map[string][]include
+}
+
+func (t *includeTable) get(page string) []include {
+	t.Lock()
+	defer t.Unlock()
+	return t.pages[page]
+}
+
+func (t *includeTable) set(page string, deps []include) {
+	t.Lock()
+	defer t.Unlock()
+	t.pages[page] = deps
+}
+
+// includeKey extends the cache key of a page at revision by the blobs of the
+// pages it includes.
+func includeKey(deps []include, revision string) string {
+	var key string
+	for _, d := range deps {
+		rev := d.Revision
+		if len(rev) == 0 {
+			rev = revision
+		}
+		blob, err := store.Blob(fileName(d.Page), rev)
+		if err != nil {
+			blob = "missing"
+		}
+		key += "|" + d.Page + "@" + d.Revision + "=" + blob
+	}
+	return key
+}
+
+func includeError(format string, args ...interface{}) string {
+	return `<span class="include-error text-danger">` + html.EscapeString(fmt.Sprintf(format, args...)) + `</span>`
+}
+
+// expandIncludes replaces the include directives in the content of page at
+// revision by the content of the pages they name, which may include pages
+// themselves:
+//
+//	{{include:shared/oncall}}
+//	{{include:shared/oncall@v2}}
+//
+// The second form pins the included page, and what it includes, to a
+// revision; pin is the revision that page itself was pinned to. Pages are
+// named from the top of the wiki. Directives in code are left alone.
+// Includes that recur or nest too deeply are replaced by an error, as are
+// missing pages. The pages included are added to deps.
+func expandIncludes(page string, content []byte, revision string, pin string, chain []string, deps *[]include) []byte {
+	chain = append(chain[:len(chain):len(chain)], page)
+	return outsideCode(content, func(text []byte) []byte {
+		return includeDirective.ReplaceAllFunc(text, func(match []byte) []byte {
+			m := includeDirective.FindSubmatch(match)
+			target := slug(string(m[1]))
+
+			for _, p := range chain {
+				if p == target {
+					return []byte(includeError("Recursive include of %s", target))
+				}
+			}
+			if len(chain) > maxIncludeDepth {
+				return []byte(includeError("Includes nested too deeply at %s", target))
+			}
+
+			pinned := pin
+			if len(m[2]) > 0 {
+				pinned = string(m[2])
+			}
+			rev := pinned
+			if len(rev) == 0 {
+				rev = revision
+			}
+			*deps = append(*deps, include{Page: target, Revision: pinned})
+			included, err := store.Read(fileName(target), rev)
+			if err != nil {
+				return []byte(includeError("Page %s to include does not exist", target))
+			}
+			_, included = frontMatter(included)
+			included = expandIncludes(target, included, rev, pinned, chain, deps)
+			return bytes.TrimSpace(included)
+		})
+	})
+}
+
+// includingResults returns the pages at HEAD that include, directly or not,
+// the pages found by a search, unless they were found themselves.
+func includingResults(results []searchResult) []searchResult {
+	directives, err := store.Grep("include:")
+	if err != nil {
+		return nil
+	}
+	includers := make(map[string][]string)
+	for _, d := range directives {
+		outsideCode([]byte(d.Content), func(text []byte) []byte {
+			for _, m := range includeDirective.FindAllSubmatch(text, -1) {
+				target := slug(string(m[1]))
+				includers[target] = append(includers[target], d.Title)
+			}
+			return text
+		})
+	}
+
+	found := make(map[string]bool)
+	var queue []string
+	for _, r := range results {
+		if !found[r.Title] {
+			found[r.Title] = true
+			queue = append(queue, r.Title)
+		}
+	}
+	var extra []searchResult
+	for len(queue) > 0 {
+		page := queue[0]
+		queue = queue[1:]
+		for _, includer := range includers[page] {
+			if !found[includer] {
+				found[includer] = true
+				queue = append(queue, includer)
+				extra = append(extra, searchResult{Title: includer, Content: "Includes " + page})
+			}
+		}
+	}
+	return extra
+}
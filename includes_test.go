@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestExpandIncludes(t *testing.T) {
+	dir := initRepo()
+	defer discardRepo(dir)
+	conf.FileExtension = "md"
+	defer func() { conf = config{} }()
+
+	a := author{Name: "Test", Email: "test@example.com"}
+	store.Write("shared/oncall.md", []byte("Call Alice."), "Add oncall", a)
+	out, _ := gitExec("rev-parse", "HEAD")
+	pin := strings.TrimSpace(out.String())
+	store.Write("shared/oncall.md", []byte("---\ntitle: On call\n---\nCall Bob. {{include:shared/phone}}"), "Change oncall", a)
+	store.Write("shared/phone.md", []byte("555-0100"), "Add phone", a)
+	store.Write("loop.md", []byte("{{include:loop}}"), "Add loop", a)
+
+	tests := map[string]string{
+		"Trouble? {{include:shared/oncall}}":                         "Trouble? Call Bob. 555-0100",
+		"Back then: {{include: shared/oncall@" + pin + "}}":          "Back then: Call Alice.",
+		"{{include:loop}}":                                           `<span class="include-error text-danger">Recursive include of loop</span>`,
+		"{{include:nowhere}}":                                        `<span class="include-error text-danger">Page nowhere to include does not exist</span>`,
+		"`{{include:shared/phone}}`\n\n    {{include:shared/phone}}": "`{{include:shared/phone}}`\n\n    {{include:shared/phone}}",
+		"~~~\n{{include:shared/phone}}\n~~~":                         "~~~\n{{include:shared/phone}}\n~~~",
+		"- Phone:\n\n    {{include:shared/phone}}":                   "- Phone:\n\n    555-0100",
+	}
+	for content, expected := range tests {
+		var deps []include
+		if expanded := expandIncludes("page", []byte(content), "HEAD", "", nil, &deps); string(expanded) != expected {
+			t.Errorf("Expansion of %q should equal >%s<, but is >%s<", content, expected, expanded)
+		}
+	}
+
+	var deps []include
+	expandIncludes("page", []byte("{{include:shared/oncall}}"), "HEAD", "", nil, &deps)
+	if fmt.Sprint(deps) != "[{shared/oncall } {shared/phone }]" {
+		t.Errorf("Dependencies should equal >[{shared/oncall } {shared/phone }]<, but are >%v<", deps)
+	}
+
+	// Each level includes the next.
+	for i := 1; i <= maxIncludeDepth+1; i++ {
+		store.Write(fmt.Sprintf("deep/%d.md", i), []byte(fmt.Sprintf("%d {{include:deep/%d}}", i, i+1)), "Add level", a)
+	}
+	expanded := expandIncludes("deep/0", []byte("{{include:deep/1}}"), "HEAD", "", nil, &deps)
+	if !strings.HasSuffix(string(expanded), "5 "+includeError("Includes nested too deeply at deep/6")) {
+		t.Errorf("Includes should stop after %d levels, but got >%s<", maxIncludeDepth, expanded)
+	}
+}
+
+func TestIncludingResults(t *testing.T) {
+	dir := initRepo()
+	defer discardRepo(dir)
+	conf.FileExtension = "md"
+	defer func() { conf = config{} }()
+
+	a := author{Name: "Test", Email: "test@example.com"}
+	store.Write("shared/phone.md", []byte("555-0100"), "Add phone", a)
+	store.Write("shared/oncall.md", []byte("Call {{include:shared/phone}}"), "Add oncall", a)
+	store.Write("runbook.md", []byte("{{include:shared/oncall}}"), "Add runbook", a)
+
+	results, _ := store.Grep("555")
+	extra := includingResults(results)
+	if fmt.Sprint(extra) != "[{shared/oncall Includes shared/phone} {runbook Includes shared/oncall}]" {
+		t.Errorf("Including pages should be found, but got >%v<", extra)
+	}
+}
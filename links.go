@@ -0,0 +1,145 @@
+package main
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"html"
+	"log"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	wikiPages    pageSet
+	markdownLink = regexp.MustCompile(`(^|[^!\]])\[([^\]]+)\]\(([^)\s]*)\)`)
+)
+
+// pageSet is the set of pages at HEAD, for telling links to pages that do
+// not exist yet apart, along with the titles of the pages looked up so far.
+// It is loaded when first needed and dropped whenever HEAD changes.
+type pageSet struct {
+	sync.Mutex
+	pages  map[string]bool
+	folded map[string]bool
+	digest string
+	titles map[string]string
+}
+
+func (s *pageSet) load() {
+	if s.pages != nil {
+		return
+	}
+	files, err := store.Files("HEAD")
+	if err != nil {
+		log.Printf("Unable to list the pages: %v\n", err)
+		return
+	}
+	s.pages, s.folded = make(map[string]bool), make(map[string]bool)
+	var names []string
+	for _, file := range files {
+		if strings.HasSuffix(file, "."+conf.FileExtension) {
+			s.pages[title(file)] = true
+			s.folded[strings.ToLower(title(file))] = true
+			names = append(names, title(file))
+		}
+	}
+	sort.Strings(names)
+	s.digest = fmt.Sprintf("%x", sha1.Sum([]byte(strings.Join(names, "\n"))))
+}
+
+// exists returns whether page exists at HEAD, as loadPage finds it,
+// regardless of case. If the pages cannot be listed, every page is taken to
+// exist.
+func (s *pageSet) exists(page string) bool {
+	s.Lock()
+	defer s.Unlock()
+	s.load()
+	return s.pages == nil || s.pages[page] || s.folded[strings.ToLower(page)]
+}
+
+// key identifies the set of pages, which changes only when a page is added
+// or removed.
+func (s *pageSet) key() string {
+	s.Lock()
+	defer s.Unlock()
+	s.load()
+	return s.digest
+}
+
+// title returns the title of page at HEAD.
+func (s *pageSet) title(page string) string {
+	s.Lock()
+	defer s.Unlock()
+	if t, ok := s.titles[page]; ok {
+		return t
+	}
+	if s.titles == nil {
+		s.titles = make(map[string]string)
+	}
+	content, _ := store.Read(fileName(page), "HEAD")
+	s.titles[page] = pageTitle(page, content)
+	return s.titles[page]
+}
+
+func (s *pageSet) reset() {
+	s.Lock()
+	defer s.Unlock()
+	s.pages, s.folded, s.digest, s.titles = nil, nil, "", nil
+}
+
+// linkedPage returns the page that a link on page goes to, if it goes to a
+// page of the wiki at all. Relative links are resolved the way the browser
+// resolves them on /view/<page>.
+func linkedPage(page string, link string) (string, bool) {
+	if i := strings.IndexAny(link, "#?"); i >= 0 {
+		link = link[:i]
+	}
+	link, err := url.PathUnescape(link)
+	if err != nil {
+		return "", false
+	}
+	switch {
+	case len(link) == 0, strings.HasSuffix(link, "/"), strings.Contains(link, ":"):
+		return "", false
+	case strings.HasPrefix(link, "/view/"):
+		link = strings.TrimPrefix(link, "/view/")
+	case strings.HasPrefix(link, "/"):
+		return "", false
+	default:
+		link = path.Join(path.Dir(page), link)
+	}
+	if validFile.MatchString("/view/"+link) || !validPath.MatchString("/view/"+link) {
+		return "", false
+	}
+	return link, true
+}
+
+// processMissingLinks marks the links on page to pages that do not exist,
+// so that they stand out and invite the reader to create the page. Links
+// written as [Title]() pass the title on to the new page. Links in code are
+// left alone.
+func processMissingLinks(content []byte, page string, exists func(string) bool) []byte {
+	return outsideCode(content, func(text []byte) []byte {
+		return missingLinks(text, page, exists)
+	})
+}
+
+func missingLinks(content []byte, page string, exists func(string) bool) []byte {
+	return markdownLink.ReplaceAllFunc(content, func(match []byte) []byte {
+		m := markdownLink.FindSubmatch(match)
+		target, ok := linkedPage(page, string(m[3]))
+		if !ok || exists(target) {
+			return match
+		}
+		href, text := string(m[3]), string(m[2])
+		if href == pageURL(slug(text)) && defaultTitle(slug(text)) != text {
+			href += "?title=" + url.QueryEscape(text)
+		}
+		return []byte(fmt.Sprintf(`%s<a href="%s" class="missing text-danger" title="Create this page">%s</a>`,
+			m[1], html.EscapeString(href), text))
+	})
+}
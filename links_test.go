@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestProcessMissingLinks(t *testing.T) {
+	pages := map[string]bool{"bicycle": true, "life/bicycle": true, "life/balance": true}
+	exists := func(page string) bool { return pages[page] }
+
+	tests := map[string]string{
+		"[bicycle](bicycle) and [unicycle](unicycle)":    `[bicycle](bicycle) and <a href="unicycle" class="missing text-danger" title="Create this page">unicycle</a>`,
+		"[balance](balance) or [goals](goals#health)":    `[balance](balance) or <a href="goals#health" class="missing text-danger" title="Create this page">goals</a>`,
+		"[up](../bicycle) and [tandem](/view/tandem)":    `[up](../bicycle) and <a href="/view/tandem" class="missing text-danger" title="Create this page">tandem</a>`,
+		"![wheel](wheel) [wheel.png](wheel.png)":         "![wheel](wheel) [wheel.png](wheel.png)",
+		"[Git](http://git-scm.com) [life](/view/life/)":  "[Git](http://git-scm.com) [life](/view/life/)",
+		"`[unicycle](unicycle)`\n\n    [tandem](tandem)": "`[unicycle](unicycle)`\n\n    [tandem](tandem)",
+		"```\n[unicycle](unicycle)\n```":                 "```\n[unicycle](unicycle)\n```",
+	}
+	for original, expected := range tests {
+		if processed := processMissingLinks([]byte(original), "life/balance", exists); string(processed) != expected {
+			t.Errorf("Expected >%s<, got >%s<\n", expected, processed)
+		}
+	}
+}
+
+func TestPageSet(t *testing.T) {
+	dir := initRepo()
+	defer discardRepo(dir)
+	conf.FileExtension = "md"
+	defer func() { conf = config{} }()
+	defer wikiPages.reset()
+
+	a := author{Name: "Test", Email: "test@example.com"}
+	store.Write("bicycle.md", []byte("Two wheels"), "Add bicycle", a)
+	wikiPages.reset()
+	key := wikiPages.key()
+	if !wikiPages.exists("bicycle") || wikiPages.exists("unicycle") {
+		t.Errorf("Only bicycle should exist")
+	}
+
+	store.Write("bicycle.md", []byte("Two wheels and a bell"), "Edit bicycle", a)
+	wikiPages.reset()
+	if wikiPages.key() != key {
+		t.Errorf("Key should stay >%s< when no page is added, but is >%s<", key, wikiPages.key())
+	}
+	store.Write("unicycle.md", []byte("One wheel"), "Add unicycle", a)
+	wikiPages.reset()
+	if !wikiPages.exists("unicycle") || wikiPages.key() == key {
+		t.Errorf("Unicycle should exist and change the key")
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	auth "github.com/abbot/go-http-auth"
+)
+
+var editLocks *lockTable
+
+// editLock tells others that a user has a page open in the editor. Locks are
+// advisory: they warn, but anyone can take them over.
+type editLock struct {
+	Username string
+	Name     string
+	Since    time.Time
+	seen     time.Time
+}
+
+// lockTable holds the edit locks by page title. A lock expires unless its
+// holder sends a heartbeat within expire.
+type lockTable struct {
+	sync.Mutex
+	expire time.Duration
+	locks  map[string]*editLock
+	now    func() time.Time
+}
+
+func newLockTable(expire time.Duration) *lockTable {
+	return &lockTable{expire: expire, locks: make(map[string]*editLock), now: time.Now}
+}
+
+// holder returns the lock on title, if it has not expired.
+func (t *lockTable) holder(title string) (*editLock, bool) {
+	l, ok := t.locks[title]
+	if !ok {
+		return nil, false
+	}
+	if t.now().Sub(l.seen) > t.expire {
+		delete(t.locks, title)
+		return nil, false
+	}
+	return l, true
+}
+
+// acquire locks title for username. If another user holds the lock, it is
+// returned instead, unless takeover is set.
+func (t *lockTable) acquire(title string, username string, name string, takeover bool) (editLock, bool) {
+	t.Lock()
+	defer t.Unlock()
+	now := t.now()
+	l, ok := t.holder(title)
+	if ok && l.Username != username && !takeover {
+		return *l, false
+	}
+	if !ok || l.Username != username {
+		l = &editLock{Username: username, Name: name, Since: now}
+		t.locks[title] = l
+	}
+	l.seen = now
+	return *l, true
+}
+
+// heartbeat keeps the lock of username on title alive. If the lock was taken
+// over in the meantime, the new holder is returned instead.
+func (t *lockTable) heartbeat(title string, username string, name string) (editLock, bool) {
+	t.Lock()
+	l, ok := t.holder(title)
+	t.Unlock()
+	if ok && l.Username != username {
+		return *l, false
+	}
+	// An expired lock is taken again, as long as nobody else took it.
+	return t.acquire(title, username, name, false)
+}
+
+// release gives up the lock of username on title.
+func (t *lockTable) release(title string, username string) {
+	t.Lock()
+	defer t.Unlock()
+	if l, ok := t.locks[title]; ok && l.Username == username {
+		delete(t.locks, title)
+	}
+}
+
+func lockName(r *auth.AuthenticatedRequest) string {
+	if name := requestAuthor(r).Name; len(name) > 0 {
+		return name
+	}
+	return r.Username
+}
+
+// lockHandler keeps the edit lock on a page alive when posted to by the
+// editor, and releases it on DELETE. If someone else took over the lock, it
+// responds with a conflict and the new holder.
+func lockHandler(w http.ResponseWriter, r *auth.AuthenticatedRequest, title string) {
+	if editLocks == nil {
+		http.NotFound(w, &r.Request)
+		return
+	}
+	switch r.Method {
+	case "POST":
+		l, ok := editLocks.heartbeat(title, r.Username, lockName(r))
+		w.Header().Set("Content-Type", "application/json")
+		if !ok {
+			w.WriteHeader(http.StatusConflict)
+		}
+		json.NewEncoder(w).Encode(struct {
+			Name  string
+			Since string
+		}{l.Name, l.Since.Format("15:04")})
+	case "DELETE":
+		editLocks.release(title, r.Username)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockTable(t *testing.T) {
+	now := time.Date(2015, 6, 1, 10, 42, 0, 0, time.UTC)
+	locks := newLockTable(2 * time.Minute)
+	locks.now = func() time.Time { return now }
+
+	if _, ok := locks.acquire("home", "alice", "Alice", false); !ok {
+		t.Fatalf("Alice should get the lock on an unlocked page")
+	}
+	now = now.Add(time.Minute)
+	l, ok := locks.acquire("home", "bob", "Bob", false)
+	if ok || l.Name != "Alice" || l.Since.Format("15:04") != "10:42" {
+		t.Errorf("Bob should be told that Alice is editing since 10:42, but got %+v", l)
+	}
+	if _, ok = locks.acquire("about", "bob", "Bob", false); !ok {
+		t.Errorf("Bob should get the lock on another page")
+	}
+
+	// Heartbeats keep the lock alive beyond expiry.
+	now = now.Add(90 * time.Second)
+	if _, ok = locks.heartbeat("home", "alice", "Alice"); !ok {
+		t.Errorf("Alice's heartbeat should keep her lock")
+	}
+	now = now.Add(90 * time.Second)
+	if _, ok = locks.acquire("home", "bob", "Bob", false); ok {
+		t.Errorf("Bob should not get the lock while Alice keeps it alive")
+	}
+
+	// Taking over makes the heartbeat of the former holder fail.
+	if l, ok = locks.acquire("home", "bob", "Bob", true); !ok || l.Username != "bob" {
+		t.Errorf("Bob should take over the lock, but got %+v", l)
+	}
+	if l, ok = locks.heartbeat("home", "alice", "Alice"); ok || l.Name != "Bob" {
+		t.Errorf("Alice's heartbeat should tell her that Bob took over, but got %+v", l)
+	}
+
+	// Releasing only releases one's own lock.
+	locks.release("home", "alice")
+	if _, ok = locks.acquire("home", "carol", "Carol", false); ok {
+		t.Errorf("Alice should not release Bob's lock")
+	}
+	locks.release("home", "bob")
+	if _, ok = locks.acquire("home", "carol", "Carol", false); !ok {
+		t.Errorf("Carol should get the lock released by Bob")
+	}
+
+	// Without heartbeats, locks expire.
+	now = now.Add(3 * time.Minute)
+	if _, ok = locks.acquire("home", "alice", "Alice", false); !ok {
+		t.Errorf("Alice should get the expired lock of Carol")
+	}
+}
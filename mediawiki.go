@@ -0,0 +1,456 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	mediawikiHeading   = regexp.MustCompile(`^(={1,6})\s*(.*?)\s*={1,6}\s*$`)
+	mediawikiList      = regexp.MustCompile(`^([*#:;]+)\s*(.*)$`)
+	mediawikiLink      = regexp.MustCompile(`\[\[([^\]|]+)(?:\|([^\]]*))?\]\]([a-z]*)`)
+	mediawikiExternal  = regexp.MustCompile(`\[((?:https?|ftp|mailto):[^\s\]]+)(?:\s+([^\]]*))?\]`)
+	mediawikiBold      = regexp.MustCompile(`'''(.+?)'''`)
+	mediawikiItalic    = regexp.MustCompile(`''(.+?)''`)
+	mediawikiMagicWord = regexp.MustCompile(`__[A-Z]+__`)
+	mediawikiNowiki    = regexp.MustCompile(`</?nowiki\s*/?>`)
+)
+
+type mediawikiSiteinfo struct {
+	Base       string `xml:"base"`
+	Namespaces []struct {
+		Key  int    `xml:"key,attr"`
+		Name string `xml:",chardata"`
+	} `xml:"namespaces>namespace"`
+}
+
+type mediawikiRevision struct {
+	ID          int       `xml:"id"`
+	Timestamp   time.Time `xml:"timestamp"`
+	Contributor struct {
+		Username string `xml:"username"`
+		IP       string `xml:"ip"`
+	} `xml:"contributor"`
+	Comment string `xml:"comment"`
+	Text    string `xml:"text"`
+}
+
+type mediawikiPage struct {
+	Title     string              `xml:"title"`
+	Namespace int                 `xml:"ns"`
+	Revisions []mediawikiRevision `xml:"revision"`
+}
+
+// mediawikiImport converts a MediaWiki dump into pages of the wiki.
+type mediawikiImport struct {
+	namespaces map[int]string
+	mainPage   string
+	templates  int
+}
+
+// importCommand runs "import mediawiki <dump.xml>".
+func importCommand(args []string) error {
+	if len(args) != 2 || args[0] != "mediawiki" {
+		return errors.New("Usage: goiki [flags] import mediawiki <dump.xml>")
+	}
+	f, err := os.Open(args[1])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return importMediaWiki(f)
+}
+
+// importMediaWiki imports every revision of every page in a MediaWiki XML
+// dump, oldest first, as a commit by the original author at the original
+// time. Namespaces other than the main one become directories.
+func importMediaWiki(r io.Reader) error {
+	m := &mediawikiImport{namespaces: make(map[int]string)}
+	type revision struct {
+		title string
+		file  string
+		mediawikiRevision
+	}
+	var revisions []revision
+	pages := 0
+
+	d := xml.NewDecoder(r)
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "siteinfo":
+			var siteinfo mediawikiSiteinfo
+			if err = d.DecodeElement(&siteinfo, &start); err != nil {
+				return err
+			}
+			for _, ns := range siteinfo.Namespaces {
+				m.namespaces[ns.Key] = ns.Name
+			}
+			if base, err := url.Parse(siteinfo.Base); err == nil {
+				m.mainPage = strings.Replace(path.Base(base.Path), "_", " ", -1)
+			}
+		case "page":
+			var p mediawikiPage
+			if err = d.DecodeElement(&p, &start); err != nil {
+				return err
+			}
+			pages++
+			file := fileName(m.page(p.Title))
+			for _, rev := range p.Revisions {
+				revisions = append(revisions, revision{p.Title, file, rev})
+			}
+		}
+	}
+
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].Timestamp.Before(revisions[j].Timestamp)
+	})
+	latest := make(map[string]string)
+	imported := 0
+	for _, rev := range revisions {
+		content := withTitle(title(rev.file), rev.title, m.markdown(rev.Text, title(rev.file)))
+		if previous, ok := latest[rev.file]; ok && previous == content {
+			continue
+		}
+		latest[rev.file] = content
+
+		message := strings.TrimSpace(rev.Comment)
+		if len(message) == 0 {
+			message = fmt.Sprintf("Import %s", rev.title)
+		}
+		if err := store.Write(rev.file, []byte(content), message, m.author(rev.mediawikiRevision)); err != nil {
+			return fmt.Errorf("Unable to import revision %d of %s: %v", rev.ID, rev.title, err)
+		}
+		imported++
+	}
+	fmt.Printf("Imported %d revisions of %d pages\n", imported, pages)
+	if m.templates > 0 {
+		fmt.Printf("Flagged %d uses of templates, which were not converted\n", m.templates)
+	}
+	return nil
+}
+
+// author is the configured user matching the contributor of rev by username
+// or name, or else the contributor by username or IP address.
+func (m *mediawikiImport) author(rev mediawikiRevision) author {
+	name := rev.Contributor.Username
+	if len(name) == 0 {
+		name = rev.Contributor.IP
+	}
+	for _, u := range conf.Users {
+		if strings.EqualFold(u.Username, name) || strings.EqualFold(u.Name, name) {
+			return author{Name: u.Name, Email: u.Email, When: rev.Timestamp}
+		}
+	}
+	return author{Name: name, When: rev.Timestamp}
+}
+
+// mediawikiSlug turns a MediaWiki title into a page name. Underscores stand
+// for spaces in MediaWiki titles.
+func mediawikiSlug(title string) string {
+	if name := slug(strings.Replace(title, "_", " ", -1)); len(name) > 0 {
+		return name
+	}
+	return "page"
+}
+
+// page returns the page for a MediaWiki title. Namespaces become
+// directories, e.g. "Talk:Main Page" becomes "talk/Main-Page", and the main
+// page becomes the index page.
+func (m *mediawikiImport) page(title string) string {
+	title = strings.TrimPrefix(strings.TrimSpace(title), ":")
+	if i := strings.Index(title, ":"); i > 0 {
+		for key, ns := range m.namespaces {
+			if key != 0 && strings.EqualFold(title[:i], ns) {
+				return strings.ToLower(mediawikiSlug(ns)) + "/" + mediawikiSlug(title[i+1:])
+			}
+		}
+	}
+	if len(m.mainPage) > 0 && strings.Replace(title, "_", " ", -1) == m.mainPage {
+		return conf.IndexPage
+	}
+	return mediawikiSlug(title)
+}
+
+// stripTemplates replaces the templates in text, which cannot be converted,
+// by a comment naming them, so that they can be found and redone by hand.
+func (m *mediawikiImport) stripTemplates(text string) string {
+	var out strings.Builder
+	depth, start := 0, 0
+	for i := 0; i < len(text); i++ {
+		switch {
+		case strings.HasPrefix(text[i:], "{{"):
+			if depth == 0 {
+				start = i
+			}
+			depth++
+			i++
+		case strings.HasPrefix(text[i:], "}}") && depth > 0:
+			depth--
+			i++
+			if depth == 0 {
+				name := strings.TrimSpace(strings.SplitN(text[start+2:i-1], "|", 2)[0])
+				name = strings.Replace(name, "--", "- -", -1)
+				fmt.Fprintf(&out, "<!-- MediaWiki template: %s -->", name)
+				m.templates++
+			}
+		case depth == 0:
+			out.WriteByte(text[i])
+		}
+	}
+	if depth > 0 {
+		out.WriteString(text[start:])
+	}
+	return out.String()
+}
+
+// splitOutsideLinks splits s at sep, except within [[links]].
+func splitOutsideLinks(s string, sep string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "[["):
+			depth++
+			i++
+		case strings.HasPrefix(s[i:], "]]") && depth > 0:
+			depth--
+			i++
+		case depth == 0 && strings.HasPrefix(s[i:], sep):
+			parts = append(parts, s[start:i])
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// inline converts the markup within a line on page.
+func (m *mediawikiImport) inline(line string, page string) string {
+	line = mediawikiLink.ReplaceAllStringFunc(line, func(match string) string {
+		parts := mediawikiLink.FindStringSubmatch(match)
+		target, label, trail := strings.TrimSpace(parts[1]), parts[2], parts[3]
+		prefix, name := "", target
+		if i := strings.Index(target, ":"); i >= 0 {
+			prefix, name = strings.ToLower(target[:i]), target[i+1:]
+		}
+		switch {
+		case prefix == "category":
+			return ""
+		case prefix == "file" || prefix == "image":
+			caption := ""
+			for _, option := range strings.Split(label, "|") {
+				switch {
+				case option == "thumb", option == "thumbnail", option == "frame", option == "frameless",
+					option == "left", option == "right", option == "center", option == "none",
+					option == "upright", strings.HasSuffix(option, "px"):
+				default:
+					caption = option
+				}
+			}
+			file := strings.Replace(strings.TrimSpace(name), " ", "_", -1)
+			return fmt.Sprintf("![%s](%s)", caption, file)
+		}
+		if len(parts[2]) == 0 {
+			label = strings.TrimPrefix(target, ":")
+		}
+		label += trail
+
+		anchor := ""
+		if i := strings.Index(target, "#"); i >= 0 {
+			target, anchor = target[:i], "#"+strings.ToLower(mediawikiSlug(target[i+1:]))
+		}
+		if len(target) == 0 {
+			return fmt.Sprintf("[%s](%s)", label, anchor)
+		}
+		name = m.page(target)
+		if !strings.Contains(page, "/") && len(parts[2]) == 0 && len(trail) == 0 && len(anchor) == 0 && slug(label) == name {
+			return fmt.Sprintf("[%s]()", label)
+		}
+		return fmt.Sprintf("[%s](/view/%s%s)", label, pageURL(name), anchor)
+	})
+	line = mediawikiExternal.ReplaceAllStringFunc(line, func(match string) string {
+		parts := mediawikiExternal.FindStringSubmatch(match)
+		if len(parts[2]) == 0 {
+			return "<" + parts[1] + ">"
+		}
+		return fmt.Sprintf("[%s](%s)", parts[2], parts[1])
+	})
+	line = mediawikiBold.ReplaceAllString(line, "**$1**")
+	line = mediawikiItalic.ReplaceAllString(line, "*$1*")
+	return line
+}
+
+// table converts the rows of a table to a Markdown table, the first row
+// being the header.
+func (m *mediawikiImport) table(rows [][]string, page string) []string {
+	columns := 0
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	var lines []string
+	for i, row := range rows {
+		cells := make([]string, columns)
+		for j := range cells {
+			if j < len(row) {
+				cells[j] = strings.Replace(m.inline(strings.TrimSpace(row[j]), page), "|", `\|`, -1)
+			}
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+		if i == 0 {
+			separator := make([]string, columns)
+			for j := range separator {
+				separator[j] = "---"
+			}
+			lines = append(lines, "| "+strings.Join(separator, " | ")+" |")
+		}
+	}
+	return lines
+}
+
+// tableCells splits a table line into cells, dropping their attributes.
+func tableCells(line string, sep string) []string {
+	var cells []string
+	for _, cell := range splitOutsideLinks(line, sep) {
+		if parts := splitOutsideLinks(cell, "|"); len(parts) > 1 && strings.Contains(parts[0], "=") {
+			cell = strings.Join(parts[1:], "|")
+		}
+		cells = append(cells, cell)
+	}
+	return cells
+}
+
+// markdown converts the wikitext of a page to Markdown: headings, lists,
+// tables, preformatted text, links and emphasis. Templates are flagged by a
+// comment.
+func (m *mediawikiImport) markdown(text string, page string) string {
+	text = strings.Replace(text, "\r\n", "\n", -1)
+	text = m.stripTemplates(text)
+	text = mediawikiMagicWord.ReplaceAllString(text, "")
+	text = mediawikiNowiki.ReplaceAllString(text, "")
+
+	var out []string
+	var rows [][]string
+	var block string
+	inTable, inPre := false, false
+	// Blocks of different kinds are separated by a blank line.
+	emit := func(kind string, lines ...string) {
+		if kind != block && len(block) > 0 && len(out) > 0 && out[len(out)-1] != "" {
+			out = append(out, "")
+		}
+		block = kind
+		out = append(out, lines...)
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case inPre:
+			if i := strings.Index(line, "</pre>"); i >= 0 {
+				if len(strings.TrimSpace(line[:i])) > 0 {
+					emit("pre", line[:i])
+				}
+				emit("pre", "```")
+				inPre = false
+			} else {
+				emit("pre", line)
+			}
+		case strings.HasPrefix(trimmed, "<pre>"):
+			content := strings.TrimPrefix(trimmed, "<pre>")
+			if i := strings.Index(content, "</pre>"); i >= 0 {
+				emit("pre", "```", content[:i], "```")
+			} else {
+				emit("pre", "```")
+				if len(content) > 0 {
+					emit("pre", content)
+				}
+				inPre = true
+			}
+		case inTable:
+			switch {
+			case strings.HasPrefix(trimmed, "|}"):
+				emit("table", m.table(rows, page)...)
+				inTable, rows = false, nil
+			case strings.HasPrefix(trimmed, "|+"):
+				emit("caption", "**"+m.inline(strings.TrimSpace(trimmed[2:]), page)+"**")
+			case strings.HasPrefix(trimmed, "|-"):
+				if len(rows[len(rows)-1]) > 0 {
+					rows = append(rows, nil)
+				}
+			case strings.HasPrefix(trimmed, "!"):
+				cells := tableCells(strings.Replace(trimmed[1:], "!!", "||", -1), "||")
+				rows[len(rows)-1] = append(rows[len(rows)-1], cells...)
+			case strings.HasPrefix(trimmed, "|"):
+				rows[len(rows)-1] = append(rows[len(rows)-1], tableCells(trimmed[1:], "||")...)
+			case len(trimmed) > 0 && len(rows[len(rows)-1]) > 0:
+				// Cells may continue on the following lines.
+				row := rows[len(rows)-1]
+				row[len(row)-1] += " " + trimmed
+			}
+		case strings.HasPrefix(trimmed, "{|"):
+			inTable, rows = true, [][]string{nil}
+		case len(trimmed) == 0:
+			out = append(out, "")
+			block = ""
+		case mediawikiHeading.MatchString(line):
+			parts := mediawikiHeading.FindStringSubmatch(line)
+			emit("heading", strings.Repeat("#", len(parts[1]))+" "+m.inline(parts[2], page))
+		case trimmed == "----" || strings.HasPrefix(trimmed, "----"):
+			emit("rule", "---")
+		case mediawikiList.MatchString(line):
+			parts := mediawikiList.FindStringSubmatch(line)
+			markers, content := parts[1], m.inline(parts[2], page)
+			indent := strings.Repeat("    ", len(markers)-1)
+			switch markers[len(markers)-1] {
+			case '*':
+				emit("list", indent+"* "+content)
+			case '#':
+				emit("list", indent+"1. "+content)
+			case ';':
+				term := strings.SplitN(content, " : ", 2)
+				if len(term) == 2 {
+					emit("definition", "**"+strings.TrimSpace(term[0])+"**: "+term[1])
+				} else {
+					emit("definition", "**"+content+"**")
+				}
+			default:
+				if strings.Trim(markers, ":") == "" {
+					emit("quote", strings.Repeat("> ", len(markers))+content)
+				} else {
+					emit("list", indent+"  "+content)
+				}
+			}
+		case strings.HasPrefix(line, " "):
+			emit("code", "    "+line[1:])
+		default:
+			emit("paragraph", m.inline(line, page))
+		}
+	}
+	if inTable {
+		out = append(out, m.table(rows, page)...)
+	}
+	if inPre {
+		out = append(out, "```")
+	}
+	return strings.TrimSpace(strings.Join(out, "\n")) + "\n"
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const mediawikiDump = `<mediawiki xmlns="http://www.mediawiki.org/xml/export-0.10/">
+  <siteinfo>
+    <sitename>Test</sitename>
+    <base>http://wiki.example.com/wiki/Main_Page</base>
+    <namespaces>
+      <namespace key="0" case="first-letter" />
+      <namespace key="1" case="first-letter">Talk</namespace>
+      <namespace key="10" case="first-letter">Template</namespace>
+    </namespaces>
+  </siteinfo>
+  <page>
+    <title>Main Page</title>
+    <ns>0</ns>
+    <revision>
+      <id>2</id>
+      <timestamp>2015-03-02T10:00:00Z</timestamp>
+      <contributor><username>Alice</username></contributor>
+      <comment>Link the FAQ</comment>
+      <text>See [[FAQ]].</text>
+    </revision>
+    <revision>
+      <id>1</id>
+      <timestamp>2015-03-01T10:00:00Z</timestamp>
+      <contributor><username>Alice</username></contributor>
+      <text>Welcome.</text>
+    </revision>
+  </page>
+  <page>
+    <title>Talk:Main Page</title>
+    <ns>1</ns>
+    <revision>
+      <id>3</id>
+      <timestamp>2015-03-03T10:00:00Z</timestamp>
+      <contributor><ip>192.0.2.1</ip></contributor>
+      <text>Nice.</text>
+    </revision>
+  </page>
+</mediawiki>`
+
+func TestMediaWikiMarkdown(t *testing.T) {
+	conf = config{FileExtension: "md", IndexPage: "index"}
+	defer func() { conf = config{} }()
+	m := &mediawikiImport{namespaces: map[int]string{1: "Talk", 6: "File"}}
+
+	tests := map[string]string{
+		"== Install ==":                         "## Install",
+		"'''bold''' and ''italic''":             "**bold** and *italic*",
+		"[[FAQ]]":                               "[FAQ]()",
+		"[[Café Menu]]":                         "[Café Menu]()",
+		"[[Café Menu|menu]]":                    "[menu](/view/Caf%C3%A9-Menu)",
+		"[[Q&A]]":                               "[Q&A]()",
+		"[[Release notes|notes]]":               "[notes](/view/Release-notes)",
+		"[[Setup#Linux]]":                       "[Setup#Linux](/view/Setup#linux)",
+		"[[Talk:Setup]]":                        "[Talk:Setup](/view/talk/Setup)",
+		"[[Category:Docs]]":                     "",
+		"[[File:Logo.png|thumb|The logo]]":      "![The logo](Logo.png)",
+		"[[Category]]":                          "[Category]()",
+		"[[File]] and [[Image]]":                "[File]() and [Image]()",
+		"[http://example.com Example]":          "[Example](http://example.com)",
+		"* one\n** two\n# three":                "* one\n    * two\n1. three",
+		"{{Stub}} text":                         "<!-- MediaWiki template: Stub --> text",
+		"{{Box|{{Nested}}}}":                    "<!-- MediaWiki template: Box -->",
+		"<pre>\ncode\n</pre>":                   "```\ncode\n```",
+		"Text\n== Next ==":                      "Text\n\n## Next",
+		"{|\n! A !! B\n|-\n| 1 || [[x|y]]\n|}":  "| A | B |\n| --- | --- |\n| 1 | [y](/view/x) |",
+		"{|\n|-\n| style=\"color:red\" | 1\n|}": "| 1 |\n| --- |",
+	}
+	for wikitext, expected := range tests {
+		if markdown := strings.TrimSpace(m.markdown(wikitext, "page")); markdown != expected {
+			t.Errorf("Markdown of %q should equal >%s<, but is >%s<", wikitext, expected, markdown)
+		}
+	}
+	if m.templates != 2 {
+		t.Errorf("Templates should equal >2<, but is >%d<", m.templates)
+	}
+}
+
+func TestImportMediaWiki(t *testing.T) {
+	dir := initRepo()
+	defer discardRepo(dir)
+	conf = config{FileExtension: "md", IndexPage: "home"}
+	conf.Users = append(conf.Users, user{Username: "alice", Name: "Alice Smith", Email: "alice@example.com"})
+	defer func() { conf = config{} }()
+
+	if err := importMediaWiki(strings.NewReader(mediawikiDump)); err != nil {
+		t.Fatalf("Unable to import the dump: %v", err)
+	}
+
+	content, err := store.Read("home.md", "HEAD")
+	if expected := "---\ntitle: Main Page\n---\n\nSee [FAQ]().\n"; err != nil || string(content) != expected {
+		t.Errorf("Main page should equal >%s<, but is >%s< (%v)", expected, content, err)
+	}
+	content, err = store.Read("talk/Main-Page.md", "HEAD")
+	if expected := "---\ntitle: Talk:Main Page\n---\n\nNice.\n"; err != nil || string(content) != expected {
+		t.Errorf("Talk page should equal >%s<, but is >%s< (%v)", expected, content, err)
+	}
+
+	out, err := gitExec("log", "--reverse", "--format=%an <%ae> %aI %s")
+	if err != nil {
+		t.Fatalf("Unable to read the log: %v", err)
+	}
+	expected := "Alice Smith <alice@example.com> 2015-03-01T10:00:00Z Import Main Page\n" +
+		"Alice Smith <alice@example.com> 2015-03-02T10:00:00Z Link the FAQ\n" +
+		"192.0.2.1 <> 2015-03-03T10:00:00Z Import Talk:Main Page\n"
+	if log := strings.Replace(out.String(), "+00:00", "Z", -1); log != expected {
+		t.Errorf("Log should equal >%s<, but is >%s<", expected, log)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// templateNamespace is the directory of the wiki with the pages that new
+// pages can start from, such as templates/runbook.
+const templateNamespace = "templates"
+
+var placeholder = regexp.MustCompile(`\{\{\s*(date|user|title)\s*\}\}`)
+
+// pageTemplates lists the names of the template pages at HEAD, without the
+// namespace.
+func pageTemplates() []string {
+	files, err := store.Files("HEAD")
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, file := range files {
+		if path.Dir(file) == templateNamespace && strings.HasSuffix(file, "."+conf.FileExtension) {
+			names = append(names, path.Base(title(file)))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fillTemplate returns the content of a template page for a new page with
+// title, created by user at now. Its placeholders are filled in:
+//
+//	{{date}}    the day the page is created, e.g. 2015-03-01
+//	{{user}}    the name of whoever creates the page
+//	{{title}}   the title of the page
+func fillTemplate(content []byte, title string, user string, now time.Time) string {
+	_, content = frontMatter(content)
+	values := map[string]string{"date": now.Format("2006-01-02"), "user": user, "title": title}
+	return placeholder.ReplaceAllStringFunc(string(content), func(match string) string {
+		return values[placeholder.FindStringSubmatch(match)[1]]
+	})
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFillTemplate(t *testing.T) {
+	template := "---\ntitle: Meeting notes\n---\n# {{title}}\n\nTaken by {{ user }} on {{date}}. {{include:shared/agenda}}"
+	now := time.Date(2015, 3, 1, 10, 0, 0, 0, time.UTC)
+	expected := "# Weekly Sync\n\nTaken by Alice on 2015-03-01. {{include:shared/agenda}}"
+	if filled := fillTemplate([]byte(template), "Weekly Sync", "Alice", now); filled != expected {
+		t.Errorf("Filled template should equal >%s<, but is >%s<", expected, filled)
+	}
+}
+
+func TestPageTemplates(t *testing.T) {
+	dir := initRepo()
+	defer discardRepo(dir)
+	conf.FileExtension = "md"
+	defer func() { conf = config{} }()
+
+	a := author{Name: "Test", Email: "test@example.com"}
+	store.Write("templates/runbook.md", []byte("# {{title}}"), "Add runbook template", a)
+	store.Write("templates/meeting-notes.md", []byte("# {{date}}"), "Add meeting notes template", a)
+	store.Write("templates/old/retired.md", []byte("Retired"), "Add retired template", a)
+	store.Write("runbook.md", []byte("Not a template"), "Add runbook", a)
+
+	if names := strings.Join(pageTemplates(), ","); names != "meeting-notes,runbook" {
+		t.Errorf("Templates should equal >meeting-notes,runbook<, but are >%s<", names)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+var redirectLine = regexp.MustCompile(`(?i)^#REDIRECT:?\s*\[([^\]]*)\]\(([^)\s]*)\)`)
+
+// redirectTarget returns the page that page redirects to, along with the
+// fragment of the redirect, if page is a redirect. A redirect is a page with
+// a redirect field in its front matter, or whose body starts with a link
+// after #REDIRECT:
+//
+//	#REDIRECT [Target]()
+func redirectTarget(page string, content []byte) (string, string, bool) {
+	fields, body := frontMatter(content)
+	var link string
+	if target, ok := fields["redirect"]; ok {
+		link = titleLink(target)
+	} else if m := redirectLine.FindSubmatch(bytes.TrimSpace(body)); m != nil {
+		link = string(m[2])
+		if len(link) == 0 {
+			link = titleLink(string(m[1]))
+		}
+	} else {
+		return "", "", false
+	}
+
+	fragment := ""
+	if i := strings.Index(link, "#"); i >= 0 {
+		fragment = link[i:]
+	}
+	target, ok := linkedPage(page, link)
+	if !ok || target == page {
+		return "", "", false
+	}
+	return target, fragment, true
+}
+
+// titleLink returns the link to the page with title, which may have a
+// fragment, and is taken from the top of the wiki if it starts with a slash.
+func titleLink(title string) string {
+	fragment := ""
+	if i := strings.Index(title, "#"); i >= 0 {
+		title, fragment = title[:i], title[i:]
+	}
+	link := pageURL(slug(title))
+	if strings.HasPrefix(strings.TrimSpace(title), "/") {
+		link = "/view/" + link
+	}
+	return link + fragment
+}
+
+// findPage returns the page named like page but for case among files, if
+// there is one.
+func findPage(page string, files []string) (string, bool) {
+	for _, file := range files {
+		if strings.HasSuffix(file, "."+conf.FileExtension) && strings.EqualFold(title(file), page) {
+			return title(file), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRedirectTarget(t *testing.T) {
+	tests := map[string]string{
+		"#REDIRECT [Onboarding]()":                "life/Onboarding",
+		"#redirect [New Hires](/view/onboarding)": "onboarding",
+		"#REDIRECT [Q&A#Setup]()":                 "life/Q-A#Setup",
+		"---\nredirect: /Team Handbook\n---\n":    "Team-Handbook",
+		"---\nredirect: Café Menu\n---\n":         "life/Café-Menu",
+	}
+	for content, expected := range tests {
+		target, fragment, ok := redirectTarget("life/welcome", []byte(content))
+		if !ok || target+fragment != expected {
+			t.Errorf("Redirect of %q should equal >%s<, but is >%s%s<", content, expected, target, fragment)
+		}
+	}
+
+	for _, content := range []string{"Welcome. #REDIRECT [Onboarding]()", "#REDIRECT [Self]()", "#REDIRECT [Git](http://git-scm.com)"} {
+		if target, _, ok := redirectTarget("life/Self", []byte(content)); ok {
+			t.Errorf("%q should not be a redirect, but redirects to >%s<", content, target)
+		}
+	}
+}
+
+func TestLoadPageRegardlessOfCase(t *testing.T) {
+	dir := initRepo()
+	defer discardRepo(dir)
+	conf.FileExtension = "md"
+	defer func() { conf = config{} }()
+
+	store.Write("onboarding.md", []byte("Welcome"), "Add onboarding", author{Name: "Test", Email: "test@example.com"})
+	p, err := loadPage("Onboarding", "HEAD")
+	if err != nil || p.Title != "onboarding" || p.Body != "Welcome" {
+		t.Errorf("Page should be found as >onboarding<, but is >%s< (%v)", p.Title, err)
+	}
+	if _, err = loadPage("Offboarding", "HEAD"); err == nil {
+		t.Errorf("Offboarding should not be found")
+	}
+
+	store = unreadableStorage{store}
+	if _, err = loadPage("Onboarding", "HEAD"); err == nil {
+		t.Errorf("Onboarding should not be found when it cannot be read")
+	}
+}
+
+// unreadableStorage lists pages that cannot be read, as when a page is
+// deleted between the two.
+type unreadableStorage struct {
+	Storage
+}
+
+func (unreadableStorage) Read(file string, revision string) ([]byte, error) {
+	return nil, errors.New("deleted")
+}
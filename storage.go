@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/VictorLowther/go-git/git"
+)
+
+var store Storage
+
+// Storage is where the wiki keeps its pages and their history. Files are
+// named relative to the data directory and revisions are anything the
+// backend can resolve, "HEAD" being the latest.
+type Storage interface {
+	// Read returns the content of file at revision.
+	Read(file string, revision string) ([]byte, error)
+	// Blob returns the hash of the content of file at revision.
+	Blob(file string, revision string) (string, error)
+	// Write stores data in file and commits it with message by author.
+	Write(file string, data []byte, message string, author author) error
+	// Log returns the revisions of file, latest first.
+	Log(file string) ([]pageRevision, error)
+	// Files lists all files at revision.
+	Files(revision string) ([]string, error)
+	// Grep returns the lines of pages in HEAD matching keyword, ignoring case.
+	Grep(keyword string) ([]searchResult, error)
+	// Head returns the hash of the latest revision, or an empty string if
+	// there is none yet.
+	Head() (string, error)
+}
+
+// branchStorage is implemented by the Git backends, which can keep drafts on
+// branches beside the current one.
+type branchStorage interface {
+	Storage
+	// WriteBranch commits data as file on branch, which is created from HEAD
+	// if it does not exist yet. The working tree is left alone.
+	WriteBranch(branch string, file string, data []byte, message string, author author) error
+	// Diff returns the changes made on branch since it forked off HEAD as a
+	// unified diff.
+	Diff(branch string) (string, error)
+	// Merge merges branch into HEAD, failing with a *mergeConflict if both
+	// changed the same file differently.
+	Merge(branch string, message string, author author) error
+	// DeleteBranch removes branch.
+	DeleteBranch(branch string) error
+}
+
+// mergeConflict lists the files that kept a merge from going through.
+type mergeConflict struct {
+	Files []string
+}
+
+func (e *mergeConflict) Error() string {
+	return "Merge conflict in " + strings.Join(e.Files, ", ")
+}
+
+// openStorage opens the data directory with the given backend: "go-git" for
+// the embedded Git implementation, "git" to run the git executable or
+// "files" for plain files without Git.
+func openStorage(backend string, dir string) (Storage, error) {
+	switch backend {
+	case "", "go-git":
+		return openGoGitStorage(dir)
+	case "git":
+		return openExecStorage(dir)
+	case "files":
+		return openFileStorage(dir, conf.Backups)
+	}
+	return nil, fmt.Errorf("Unknown storage backend %q", backend)
+}
+
+// grepPattern compiles a case insensitive search for keyword, which is taken
+// literally unless it is a valid regular expression.
+func grepPattern(keyword string) *regexp.Regexp {
+	re, err := regexp.Compile("(?i)" + keyword)
+	if err != nil {
+		re = regexp.MustCompile("(?i)" + regexp.QuoteMeta(keyword))
+	}
+	return re
+}
+
+// grepLines returns the lines of file matching re.
+func grepLines(re *regexp.Regexp, file string, content []byte) []searchResult {
+	var results []searchResult
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if re.MatchString(scanner.Text()) {
+			results = append(results, searchResult{Title: title(file), Content: scanner.Text()})
+		}
+	}
+	return results
+}
+
+// execStorage runs the git executable for every operation.
+type execStorage struct {
+	dir string
+}
+
+func openExecStorage(dir string) (*execStorage, error) {
+	var err error
+	if repo, err = git.Open(dir); err != nil {
+		return nil, err
+	}
+	return &execStorage{dir: dir}, nil
+}
+
+func (s *execStorage) Read(file string, revision string) ([]byte, error) {
+	out, err := gitShow(file, revision)
+	return out.Bytes(), err
+}
+
+func (s *execStorage) Blob(file string, revision string) (string, error) {
+	return gitBlob(file, revision)
+}
+
+func (s *execStorage) Write(file string, data []byte, message string, author author) error {
+	datapath := dataPath(s.dir, file)
+	err := os.MkdirAll(filepath.Dir(datapath), 0777)
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(datapath, data, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = gitAdd(file)
+	if err != nil {
+		return err
+	}
+	_, err = gitCommit(message, author)
+	return err
+}
+
+func (s *execStorage) Log(file string) ([]pageRevision, error) {
+	return gitLog(file)
+}
+
+func (s *execStorage) Files(revision string) ([]string, error) {
+	out, err := gitExec("ls-tree", "-r", "-z", "--name-only", revision)
+	if err != nil {
+		return nil, err
+	}
+	files := strings.Split(strings.TrimRight(out.String(), "\x00"), "\x00")
+	if len(files) == 1 && files[0] == "" {
+		return nil, nil
+	}
+	return files, nil
+}
+
+func (s *execStorage) Grep(keyword string) ([]searchResult, error) {
+	return gitGrep(keyword)
+}
+
+func (s *execStorage) Head() (string, error) {
+	out, err := gitExec("rev-parse", "--verify", "--quiet", "HEAD")
+	if err != nil && out.Len() == 0 {
+		// An empty repo has no HEAD yet.
+		return "", nil
+	}
+	return strings.TrimSpace(out.String()), err
+}
+
+func (s *execStorage) WriteBranch(branch string, file string, data []byte, message string, author author) error {
+	return gitWriteBranch(branch, file, data, message, author)
+}
+
+func (s *execStorage) Diff(branch string) (string, error) {
+	out, err := gitDiff(branch)
+	return out.String(), err
+}
+
+func (s *execStorage) Merge(branch string, message string, author author) error {
+	return gitMerge(branch, message, author)
+}
+
+func (s *execStorage) DeleteBranch(branch string) error {
+	_, err := gitDeleteBranch(branch)
+	return err
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testStorage(t *testing.T, s Storage) {
+	conf.FileExtension = "md"
+	defer func() { conf = config{} }()
+
+	if head, err := s.Head(); head != "" || err != nil {
+		t.Errorf(`HEAD of an empty repo should equal "", but was "%s" (error: %v)`, head, err)
+	}
+
+	a := author{Name: "Test", Email: "test@example.com"}
+	if err := s.Write("life/bicycle.md", []byte("Keep moving."), "Add bicycle", a); err != nil {
+		t.Fatalf("Unable to write: %v", err)
+	}
+	first, _ := s.Head()
+	if err := s.Write("life/bicycle.md", []byte("Keep your balance."), "Update bicycle", a); err != nil {
+		t.Fatalf("Unable to write: %v", err)
+	}
+	s.Write("home.md", []byte("Life is like riding a [life/bicycle]()."), "Add home", a)
+
+	content, err := s.Read("life/bicycle.md", "HEAD")
+	if string(content) != "Keep your balance." || err != nil {
+		t.Errorf(`Content at HEAD should equal "Keep your balance.", but was "%s" (error: %v)`, content, err)
+	}
+	content, err = s.Read("life/bicycle.md", first)
+	if string(content) != "Keep moving." || err != nil {
+		t.Errorf(`Content at %s should equal "Keep moving.", but was "%s" (error: %v)`, first, content, err)
+	}
+	if _, err = s.Read("missing.md", "HEAD"); err == nil {
+		t.Errorf("Reading a missing file should fail")
+	}
+
+	// Blobs are named by content, whatever the backend.
+	blob, err := s.Blob("life/bicycle.md", first)
+	if blob != "f1865fe1fd79767d7aed8c3ebe6a4d7ad02a39be" || err != nil {
+		t.Errorf("Blob should equal >f1865fe1fd79767d7aed8c3ebe6a4d7ad02a39be<, but was >%s< (error: %v)", blob, err)
+	}
+
+	revisions, err := s.Log("life/bicycle.md")
+	if len(revisions) != 2 || err != nil {
+		t.Fatalf("Number of revisions should equal 2, but was %d (error: %v)", len(revisions), err)
+	}
+	if revisions[1].Description != "Add bicycle" || revisions[0].Author.Name != "Test" || revisions[0].Title != "life/bicycle" {
+		t.Errorf("Revisions should describe the commits, but were %+v", revisions)
+	}
+
+	files, err := s.Files("HEAD")
+	if len(files) != 2 || files[0] != "home.md" || files[1] != "life/bicycle.md" {
+		t.Errorf("Files should equal [home.md life/bicycle.md], but were %v (error: %v)", files, err)
+	}
+
+	results, err := s.Grep("BALANCE")
+	if len(results) != 1 || results[0].Title != "life/bicycle" || results[0].Content != "Keep your balance." {
+		t.Errorf("Search results should contain one line of life/bicycle, but were %+v (error: %v)", results, err)
+	}
+}
+
+func testBranches(t *testing.T, s branchStorage, dir string) {
+	conf.FileExtension = "md"
+	defer func() { conf = config{} }()
+
+	a := author{Name: "Test", Email: "test@example.com"}
+	s.Write("home.md", []byte("Home."), "Add home", a)
+	s.Write("life/bicycle.md", []byte("Keep moving."), "Add bicycle", a)
+
+	if err := s.WriteBranch("drafts/rewrite", "life/bicycle.md", []byte("Keep your balance."), "Rewrite bicycle", a); err != nil {
+		t.Fatalf("Unable to write to the draft branch: %v", err)
+	}
+	content, _ := s.Read("life/bicycle.md", "HEAD")
+	if string(content) != "Keep moving." {
+		t.Errorf(`Content at HEAD should equal "Keep moving.", but was "%s"`, content)
+	}
+	content, _ = ioutil.ReadFile(dataPath(dir, "life/bicycle.md"))
+	if string(content) != "Keep moving." {
+		t.Errorf(`Content in the working tree should equal "Keep moving.", but was "%s"`, content)
+	}
+	content, err := s.Read("life/bicycle.md", "drafts/rewrite")
+	if string(content) != "Keep your balance." || err != nil {
+		t.Errorf(`Content of the draft should equal "Keep your balance.", but was "%s" (error: %v)`, content, err)
+	}
+
+	diff, err := s.Diff("drafts/rewrite")
+	if !strings.Contains(diff, "-Keep moving.") || !strings.Contains(diff, "+Keep your balance.") || strings.Contains(diff, "home.md") {
+		t.Errorf("Diff should only change life/bicycle.md, but was >%s< (error: %v)", diff, err)
+	}
+
+	s.Write("home.md", []byte("Welcome."), "Update home", a)
+	if err = s.Merge("drafts/rewrite", "Merge rewrite", a); err != nil {
+		t.Fatalf("Unable to merge: %v", err)
+	}
+	content, _ = s.Read("life/bicycle.md", "HEAD")
+	if string(content) != "Keep your balance." {
+		t.Errorf(`Merged content should equal "Keep your balance.", but was "%s"`, content)
+	}
+	content, _ = ioutil.ReadFile(dataPath(dir, "life/bicycle.md"))
+	if string(content) != "Keep your balance." {
+		t.Errorf(`Merged content in the working tree should equal "Keep your balance.", but was "%s"`, content)
+	}
+	content, _ = s.Read("home.md", "HEAD")
+	if string(content) != "Welcome." {
+		t.Errorf(`Content of home.md should equal "Welcome.", but was "%s"`, content)
+	}
+
+	s.Write("life/checklist.md", []byte("Pump tires.\nOil chain.\nCheck brakes.\nRide.\n"), "Add checklist", a)
+	s.WriteBranch("drafts/lines", "life/checklist.md", []byte("Pump tires.\nOil the chain.\nCheck brakes.\nRide.\n"), "Reword oiling", a)
+	s.Write("life/checklist.md", []byte("Pump tires.\nOil chain.\nCheck brakes.\nRide safely.\n"), "Reword riding", a)
+	if err = s.Merge("drafts/lines", "Merge lines", a); err != nil {
+		t.Errorf("Changes to different lines should merge, but got %v", err)
+	}
+	content, _ = s.Read("life/checklist.md", "HEAD")
+	if string(content) != "Pump tires.\nOil the chain.\nCheck brakes.\nRide safely.\n" {
+		t.Errorf("Merged content should have both changes, but was >%s<", content)
+	}
+
+	s.WriteBranch("drafts/conflict", "home.md", []byte("Hello."), "Greet", a)
+	s.Write("home.md", []byte("Hi."), "Greet differently", a)
+	err = s.Merge("drafts/conflict", "Merge conflict", a)
+	if conflict, ok := err.(*mergeConflict); !ok || len(conflict.Files) != 1 || conflict.Files[0] != "home.md" {
+		t.Errorf("Merge should fail with a conflict in home.md, but got %v", err)
+	}
+	content, _ = ioutil.ReadFile(dataPath(dir, "home.md"))
+	if string(content) != "Hi." {
+		t.Errorf(`Content after a failed merge should equal "Hi.", but was "%s"`, content)
+	}
+
+	if err = s.DeleteBranch("drafts/conflict"); err != nil {
+		t.Errorf("Unable to delete the draft branch: %v", err)
+	}
+	if _, err = s.Read("home.md", "drafts/conflict"); err == nil {
+		t.Errorf("Reading from a deleted branch should fail")
+	}
+}
+
+func TestExecStorageBranches(t *testing.T) {
+	dir := initRepo()
+	defer discardRepo(dir)
+	testBranches(t, store.(branchStorage), dir)
+}
+
+func TestGoGitStorageBranches(t *testing.T) {
+	dir := initRepo()
+	defer discardRepo(dir)
+	s, err := openGoGitStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBranches(t, s, dir)
+}
+
+func TestExecStorage(t *testing.T) {
+	dir := initRepo()
+	defer discardRepo(dir)
+	testStorage(t, store)
+}
+
+func TestGoGitStorage(t *testing.T) {
+	dir := initRepo()
+	defer discardRepo(dir)
+	s, err := openGoGitStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStorage(t, s)
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	times := map[time.Duration]string{
+		time.Second:          "1 second ago",
+		45 * time.Second:     "45 seconds ago",
+		10 * time.Minute:     "10 minutes ago",
+		5 * time.Hour:        "5 hours ago",
+		3 * 24 * time.Hour:   "3 days ago",
+		21 * 24 * time.Hour:  "3 weeks ago",
+		90 * 24 * time.Hour:  "3 months ago",
+		800 * 24 * time.Hour: "2 years ago",
+	}
+	for ago, expected := range times {
+		if relative := relativeTime(now.Add(-ago), now); relative != expected {
+			t.Errorf("Time %v ago should be described as >%s<, but was >%s<", ago, expected, relative)
+		}
+	}
+}
+
+func TestFileStorage(t *testing.T) {
+	conf.FileExtension = "md"
+	defer func() { conf = config{} }()
+	dir, _ := ioutil.TempDir("./", "files-test")
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	s, _ := openFileStorage(dir, true)
+	s.now = func() time.Time { return now }
+
+	a := author{Name: "Test", Email: "test@example.com"}
+	s.Write("life/bicycle.md", []byte("Keep moving."), "Add bicycle", a)
+	now = now.Add(time.Hour)
+	s.Write("life/bicycle.md", []byte("Keep your balance."), "Update bicycle", a)
+
+	content, err := s.Read("life/bicycle.md", "HEAD")
+	if string(content) != "Keep your balance." || err != nil {
+		t.Errorf(`Content at HEAD should equal "Keep your balance.", but was "%s" (error: %v)`, content, err)
+	}
+	blob, _ := s.Blob("life/bicycle.md", "20150601T120000.000000000")
+	if blob != "f1865fe1fd79767d7aed8c3ebe6a4d7ad02a39be" {
+		t.Errorf("Blob should equal >f1865fe1fd79767d7aed8c3ebe6a4d7ad02a39be<, but was >%s<", blob)
+	}
+
+	revisions, err := s.Log("life/bicycle.md")
+	if len(revisions) != 2 || revisions[0].Description != "Update bicycle" || revisions[1].Timestamp != "60 minutes ago" {
+		t.Errorf("Revisions should list both backups, latest first, but were %+v (error: %v)", revisions, err)
+	}
+	content, _ = s.Read("life/bicycle.md", revisions[1].Object)
+	if string(content) != "Keep moving." {
+		t.Errorf(`Content at %s should equal "Keep moving.", but was "%s"`, revisions[1].Object, content)
+	}
+
+	files, _ := s.Files("HEAD")
+	if len(files) != 1 || files[0] != "life/bicycle.md" {
+		t.Errorf("Files should equal [life/bicycle.md] without backups, but were %v", files)
+	}
+	if results, _ := s.Grep("balance"); len(results) != 1 {
+		t.Errorf("Number of search results should equal 1, but was %d", len(results))
+	}
+
+	s.backups = false
+	if _, err = s.Log("life/bicycle.md"); err != errNoHistory {
+		t.Errorf("Log without backups should fail with errNoHistory, but got %v", err)
+	}
+}
+
+func TestMergeLines(t *testing.T) {
+	base := "a\nb\nc\nd\ne\n"
+	tests := []struct {
+		ours, theirs, merged string
+	}{
+		{"a\nB\nc\nd\ne\n", "a\nb\nc\nd\nE\n", "a\nB\nc\nd\nE\n"},
+		{"x\na\nb\nc\nd\ne\n", "a\nb\nc\ne\n", "x\na\nb\nc\ne\n"},
+		{"a\nB\nc\nd\ne\n", "a\nB\nc\nd\ne\n", "a\nB\nc\nd\ne\n"},
+		{"a\nb\nc\nd\ne\n", "a\nb\nC\nd\ne\nf\n", "a\nb\nC\nd\ne\nf\n"},
+	}
+	for _, test := range tests {
+		if merged, ok := mergeLines(base, test.ours, test.theirs); !ok || merged != test.merged {
+			t.Errorf("Merge of %q and %q should equal %q, but is %q (%v)", test.ours, test.theirs, test.merged, merged, ok)
+		}
+	}
+
+	conflicts := [][2]string{{"a\nB\nc\nd\ne\n", "a\nX\nc\nd\ne\n"}, {"a\nB\nc\nd\ne\n", "a\nb\nC\nd\ne\n"}}
+	for _, c := range conflicts {
+		if merged, ok := mergeLines(base, c[0], c[1]); ok {
+			t.Errorf("Merge of %q and %q should conflict, but is %q", c[0], c[1], merged)
+		}
+	}
+
+	var lines []string
+	for i := 0; i < 50000; i++ {
+		lines = append(lines, fmt.Sprintf("line %d\n", i))
+	}
+	big := strings.Join(lines, "")
+	ours, theirs := "first\n"+big, big+"last\n"
+	if merged, ok := mergeLines(big, ours, theirs); !ok || merged != "first\n"+big+"last\n" {
+		t.Errorf("Merge of long pages should keep both edits, but is %d bytes (%v)", len(merged), ok)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	auth "github.com/abbot/go-http-auth"
+)
+
+var (
+	// repoLock serializes everything that changes the repo or its working
+	// tree, so that a scheduled sync never runs in the middle of a save.
+	repoLock sync.Mutex
+	syncs    syncStatus
+)
+
+// syncStatus is the outcome of the latest synchronizations with the remote.
+type syncStatus struct {
+	sync.Mutex
+	LastPull  time.Time
+	LastPush  time.Time
+	PullError string
+	PushError string
+	Conflicts []string
+}
+
+type syncPage struct {
+	SiteName  string
+	Title     string
+	Theme     string
+	Remote    string
+	Branch    string
+	Strategy  string
+	Interval  time.Duration
+	LastPull  time.Time
+	LastPush  time.Time
+	PullError string
+	PushError string
+	Conflicts []string
+}
+
+func syncEnabled() bool {
+	return len(conf.Sync.Remote) > 0
+}
+
+func syncRebase() bool {
+	return conf.Sync.Strategy != "merge"
+}
+
+// syncPull pulls the configured branch from the remote. A pull that runs
+// into conflicts is aborted, leaving the working tree as it was, and the
+// conflicting files are recorded for the admin page. The caller must hold
+// repoLock.
+func syncPull() error {
+	_, err := gitPull(conf.Sync.Remote, conf.Sync.Branch, syncRebase())
+	var conflicts []string
+	if err != nil {
+		conflicts, _ = gitConflicts()
+		if len(conflicts) > 0 {
+			if _, abortErr := gitAbort(syncRebase()); abortErr != nil {
+				log.Printf("Unable to abort the pull from %s: %v\n", conf.Sync.Remote, abortErr)
+			}
+			err = fmt.Errorf("Conflicts pulling from %s %s: %v", conf.Sync.Remote, conf.Sync.Branch, err)
+		}
+		log.Println(err)
+	}
+
+	syncs.Lock()
+	defer syncs.Unlock()
+	syncs.Conflicts = conflicts
+	if err != nil {
+		syncs.PullError = err.Error()
+		return err
+	}
+	syncs.LastPull = time.Now()
+	syncs.PullError = ""
+	return nil
+}
+
+// syncPush pushes HEAD to the configured branch of the remote. The caller
+// must hold repoLock.
+func syncPush() error {
+	_, err := gitPush(conf.Sync.Remote, conf.Sync.Branch)
+
+	syncs.Lock()
+	defer syncs.Unlock()
+	if err != nil {
+		log.Printf("Unable to push to %s %s: %v\n", conf.Sync.Remote, conf.Sync.Branch, err)
+		syncs.PushError = err.Error()
+		return err
+	}
+	syncs.LastPush = time.Now()
+	syncs.PushError = ""
+	return nil
+}
+
+// syncNow pulls from and pushes to the remote, pushing local commits that
+// an earlier push failed to deliver.
+func syncNow() error {
+	repoLock.Lock()
+	defer repoLock.Unlock()
+	defer checkHead()
+	if err := syncPull(); err != nil {
+		return err
+	}
+	return syncPush()
+}
+
+// syncSchedule synchronizes with the remote every interval.
+func syncSchedule(interval time.Duration) {
+	for range time.Tick(interval) {
+		syncNow()
+	}
+}
+
+func syncHandler(w http.ResponseWriter, r *auth.AuthenticatedRequest) {
+	if !conf.Auth[r.Username].Admin {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if !syncEnabled() {
+		http.NotFound(w, &r.Request)
+		return
+	}
+	if r.Method == "POST" {
+		syncNow()
+		http.Redirect(w, &r.Request, r.URL.Path, http.StatusFound)
+		return
+	}
+
+	syncs.Lock()
+	p := &syncPage{
+		Title:     "Synchronization",
+		Theme:     conf.Theme,
+		SiteName:  conf.Name,
+		Remote:    conf.Sync.Remote,
+		Branch:    conf.Sync.Branch,
+		Strategy:  conf.Sync.Strategy,
+		Interval:  conf.Sync.Interval.Duration,
+		LastPull:  syncs.LastPull,
+		LastPush:  syncs.LastPush,
+		PullError: syncs.PullError,
+		PushError: syncs.PushError,
+		Conflicts: syncs.Conflicts,
+	}
+	syncs.Unlock()
+	renderTemplate(w, "sync", p)
+}
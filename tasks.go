@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	auth "github.com/abbot/go-http-auth"
+)
+
+var (
+	taskItem     = regexp.MustCompile(`^(\s*(?:[-*+]|\d+[.)])\s+)\[([ xX])\](\s+)(.*?)\r?$`)
+	taskMark     = regexp.MustCompile(`(<li>(?:<p>)?)?goikitask(\d+)x`)
+	includedTask = regexp.MustCompile(`<li>(<p>)?\[([ xX])\]\s`)
+
+	errTaskMoved = errors.New("The task has changed since the page was shown; reload the page to see the changes")
+)
+
+// task is an item of a task list, on a line of a page counting from 1.
+type task struct {
+	Line int
+	Text string
+	Done bool
+}
+
+// markTasks replaces the boxes of the task list items in content by
+// placeholders that markdown leaves alone, returning the tasks they stand for:
+//
+//	Release checklist:
+//	- [ ] deploy canary
+//	- [x] tag release
+//
+// Tasks in code blocks, as told by codeLines, are left alone.
+func markTasks(content []byte) ([]byte, []task) {
+	var tasks []task
+	lines := strings.Split(string(content), "\n")
+	code := codeLines(lines)
+	for i, line := range lines {
+		m := taskItem.FindStringSubmatchIndex(line)
+		if code[i] || m == nil {
+			continue
+		}
+		tasks = append(tasks, task{Line: i + 1, Text: line[m[8]:m[9]], Done: line[m[4]] != ' '})
+		lines[i] = line[:m[3]] + fmt.Sprintf("goikitask%dx", len(tasks)-1) + line[m[6]:]
+	}
+	return []byte(strings.Join(lines, "\n")), tasks
+}
+
+// checkTasks turns the placeholders of markTasks in the rendered content into
+// checkboxes, which are disabled until the page enables them. Placeholders
+// that did not end up in a list item, such as in indented code, are put back
+// as they were. Tasks on included pages get checkboxes that stay disabled.
+func checkTasks(content []byte, tasks []task) []byte {
+	content = taskMark.ReplaceAllFunc(content, func(mark []byte) []byte {
+		m := taskMark.FindSubmatch(mark)
+		i, err := strconv.Atoi(string(m[2]))
+		if err != nil || i >= len(tasks) {
+			return mark
+		}
+		t := tasks[i]
+		if len(m[1]) == 0 {
+			if t.Done {
+				return []byte("[x]")
+			}
+			return []byte("[ ]")
+		}
+		checked := ""
+		if t.Done {
+			checked = ` checked="checked"`
+		}
+		return []byte(fmt.Sprintf(`%s<input type="checkbox" class="task" data-line="%d" data-text="%s"%s disabled="disabled" />`, m[1], t.Line, html.EscapeString(t.Text), checked))
+	})
+	return includedTask.ReplaceAllFunc(content, func(item []byte) []byte {
+		m := includedTask.FindSubmatch(item)
+		checked := ""
+		if m[2][0] != ' ' {
+			checked = ` checked="checked"`
+		}
+		return []byte(fmt.Sprintf(`<li>%s<input type="checkbox" class="task"%s disabled="disabled" /> `, m[1], checked))
+	})
+}
+
+// toggleTask checks or unchecks the task with text on line of content,
+// returning the content changed. It fails with errTaskMoved unless the line
+// still holds the task, unchecked or checked as it was.
+func toggleTask(content string, line int, text string, done bool) (string, error) {
+	lines := strings.Split(content, "\n")
+	if line < 1 || line > len(lines) {
+		return "", errTaskMoved
+	}
+	l := lines[line-1]
+	m := taskItem.FindStringSubmatchIndex(l)
+	if m == nil || l[m[8]:m[9]] != text || (l[m[4]] != ' ') == done {
+		return "", errTaskMoved
+	}
+	box := " "
+	if done {
+		box = "x"
+	}
+	lines[line-1] = l[:m[4]] + box + l[m[5]:]
+	return strings.Join(lines, "\n"), nil
+}
+
+// taskHandler checks or unchecks a task of a page, as it was shown, and saves
+// the page with a message naming the task.
+func taskHandler(w http.ResponseWriter, r *auth.AuthenticatedRequest, title string) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	line, err := strconv.Atoi(r.FormValue("line"))
+	if err != nil {
+		http.Error(w, "Invalid line", http.StatusBadRequest)
+		return
+	}
+	text := r.FormValue("text")
+	done := r.FormValue("done") == "true"
+
+	draft := requestDraft(&r.Request)
+	revision := "HEAD"
+	if len(draft) > 0 {
+		revision = draftBranch(draft)
+	}
+	p, err := loadPage(title, revision)
+	if err != nil {
+		http.NotFound(w, &r.Request)
+		return
+	}
+	if p.Body, err = toggleTask(p.Body, line, text, done); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	verb := "Check"
+	if !done {
+		verb = "Uncheck"
+	}
+	p.Description = fmt.Sprintf("%s '%s' in %s", verb, text, p.Title)
+	p.Author = requestAuthor(r)
+	p.Draft = draft
+	if err = p.save(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderTasks(t *testing.T) {
+	content := "Release\n\n- [ ] deploy canary\n- [x] tag \"v2\"\n\nNot tasks:\n\n```\n- [ ] not a task\n```\n\n~~~\n- [ ] nor this\n~~~\n\n    - [ ] nor this\n\nLater:\n\n- [ ] announce\n\n    - [ ] blog\n"
+	marked, tasks := markTasks([]byte(content))
+	if len(tasks) != 4 || tasks[0] != (task{Line: 3, Text: "deploy canary"}) || tasks[1] != (task{Line: 4, Text: `tag "v2"`, Done: true}) ||
+		tasks[2] != (task{Line: 20, Text: "announce"}) || tasks[3] != (task{Line: 22, Text: "blog"}) {
+		t.Errorf("Tasks should be found on lines 3, 4, 20 and 22, but are %+v", tasks)
+	}
+	html := string(checkTasks(renderMarkdown(marked), tasks))
+	expected := `<li><input type="checkbox" class="task" data-line="3" data-text="deploy canary" disabled="disabled" /> deploy canary</li>
+<li><input type="checkbox" class="task" data-line="4" data-text="tag &#34;v2&#34;" checked="checked" disabled="disabled" /> tag &ldquo;v2&rdquo;</li>`
+	if !strings.Contains(html, expected) {
+		t.Errorf("Tasks should be rendered as >%s<, but are >%s<", expected, html)
+	}
+	if !strings.Contains(html, "<code>- [ ] not a task\n</code>") {
+		t.Errorf("Tasks in code should be left alone, but are >%s<", html)
+	}
+
+	included := string(checkTasks([]byte("<li>[x] from another page</li>"), nil))
+	if included != `<li><input type="checkbox" class="task" checked="checked" disabled="disabled" /> from another page</li>` {
+		t.Errorf("Included tasks should not be toggled, but are >%s<", included)
+	}
+}
+
+func TestToggleTask(t *testing.T) {
+	content := "- [ ] deploy canary\r\n- [x] tag release\r\n"
+	toggled, err := toggleTask(content, 1, "deploy canary", true)
+	if err != nil || toggled != "- [x] deploy canary\r\n- [x] tag release\r\n" {
+		t.Errorf("Task should be checked, but is >%s< (%v)", toggled, err)
+	}
+	toggled, err = toggleTask(content, 2, "tag release", false)
+	if err != nil || toggled != "- [ ] deploy canary\r\n- [ ] tag release\r\n" {
+		t.Errorf("Task should be unchecked, but is >%s< (%v)", toggled, err)
+	}
+
+	moved := map[int]string{2: "deploy canary", 1: "deploy", 5: "deploy canary"}
+	for line, text := range moved {
+		if _, err = toggleTask(content, line, text, true); err != errTaskMoved {
+			t.Errorf("Task >%s< on line %d should have moved, but got %v", text, line, err)
+		}
+	}
+	if _, err = toggleTask(content, 2, "tag release", true); err != errTaskMoved {
+		t.Errorf("Task checked since should not be checked again, but got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// throttle counts failed logins per key (a remote address or a username) and
+// tells how long further attempts for that key must wait. Every failure
+// doubles the wait, starting at backoff and capped at maxBackoff. After
+// maxFailures consecutive failures the key is locked out for lockout.
+type throttle struct {
+	sync.Mutex
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	maxFailures int
+	lockout     time.Duration
+	attempts    map[string]*attempt
+	now         func() time.Time
+}
+
+type attempt struct {
+	failures int
+	last     time.Time
+	until    time.Time
+}
+
+func newThrottle(backoff, maxBackoff time.Duration, maxFailures int, lockout time.Duration) *throttle {
+	return &throttle{
+		backoff:     backoff,
+		maxBackoff:  maxBackoff,
+		maxFailures: maxFailures,
+		lockout:     lockout,
+		attempts:    make(map[string]*attempt),
+		now:         time.Now,
+	}
+}
+
+// wait returns the remaining time the first blocked key has to wait, or zero
+// if none of the keys are blocked.
+func (t *throttle) wait(keys ...string) time.Duration {
+	t.Lock()
+	defer t.Unlock()
+	now := t.now()
+	for _, key := range keys {
+		a, ok := t.attempts[key]
+		if ok && a.until.After(now) {
+			return a.until.Sub(now)
+		}
+	}
+	return 0
+}
+
+// fail records a failed login for each key.
+func (t *throttle) fail(keys ...string) {
+	t.Lock()
+	defer t.Unlock()
+	now := t.now()
+	t.prune(now)
+	for _, key := range keys {
+		a, ok := t.attempts[key]
+		if !ok {
+			a = &attempt{}
+			t.attempts[key] = a
+		}
+		a.failures++
+		a.last = now
+		if t.maxFailures > 0 && a.failures >= t.maxFailures {
+			a.until = now.Add(t.lockout)
+			continue
+		}
+		delay := t.backoff << uint(a.failures-1)
+		if delay <= 0 || (t.maxBackoff > 0 && delay > t.maxBackoff) {
+			delay = t.maxBackoff
+		}
+		a.until = now.Add(delay)
+	}
+}
+
+// succeed clears the failures recorded for each key.
+func (t *throttle) succeed(keys ...string) {
+	t.Lock()
+	defer t.Unlock()
+	for _, key := range keys {
+		delete(t.attempts, key)
+	}
+}
+
+// prune forgets keys whose last failure is older than the lockout period and
+// that are no longer blocked, so the map does not grow without bounds.
+func (t *throttle) prune(now time.Time) {
+	for key, a := range t.attempts {
+		if a.until.Before(now) && now.Sub(a.last) > t.lockout {
+			delete(t.attempts, key)
+		}
+	}
+}
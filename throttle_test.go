@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThrottleBackoff(t *testing.T) {
+	now := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	th := newThrottle(time.Second, 4*time.Second, 0, time.Minute)
+	th.now = func() time.Time { return now }
+
+	waits := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second}
+	for i, expected := range waits {
+		th.fail("user:alice")
+		if wait := th.wait("user:alice"); wait != expected {
+			t.Errorf("Wait after %d failures should equal >%v<, but is >%v<", i+1, expected, wait)
+		}
+	}
+
+	if wait := th.wait("user:bob"); wait != 0 {
+		t.Errorf("Wait for an unknown key should equal >0<, but is >%v<", wait)
+	}
+
+	now = now.Add(5 * time.Second)
+	if wait := th.wait("user:alice"); wait != 0 {
+		t.Errorf("Wait after the backoff has passed should equal >0<, but is >%v<", wait)
+	}
+}
+
+func TestThrottleLockout(t *testing.T) {
+	now := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	th := newThrottle(time.Second, time.Minute, 3, 15*time.Minute)
+	th.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		th.fail("address:10.0.0.1", "user:alice")
+	}
+	if wait := th.wait("address:10.0.0.2", "user:alice"); wait != 15*time.Minute {
+		t.Errorf("Wait after lockout should equal >%v<, but is >%v<", 15*time.Minute, wait)
+	}
+
+	th.succeed("user:alice")
+	if wait := th.wait("user:alice"); wait != 0 {
+		t.Errorf("Wait after a successful login should equal >0<, but is >%v<", wait)
+	}
+	if wait := th.wait("address:10.0.0.1"); wait != 15*time.Minute {
+		t.Errorf("Address should stay locked out after another user's login, but waits >%v<", wait)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Pages have a title, which is what readers see and links are written with,
+// and a name, which is what the page is stored and served as. The name is
+// derived from the title by slug. A title that cannot be derived back from
+// the name, by turning its dashes into spaces, is kept in the front matter
+// of the page:
+//
+//	---
+//	title: Q&A
+//	---
+
+// slug returns the page name for title. Letters and digits of any script are
+// kept, normalized to NFC, and anything else but dashes, underscores and
+// slashes becomes a dash, e.g. "Café Menu" becomes "Café-Menu".
+func slug(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range norm.NFC.String(strings.TrimSpace(title)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsNumber(r) || r == '_':
+			b.WriteRune(r)
+			dash = false
+		case r == '/':
+			s := strings.TrimSuffix(b.String(), "-")
+			b.Reset()
+			b.WriteString(s)
+			b.WriteRune(r)
+			dash = true
+		case !dash:
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	name := strings.Trim(b.String(), "-/")
+	for strings.Contains(name, "//") {
+		name = strings.Replace(name, "//", "/", -1)
+	}
+	return name
+}
+
+// pageURL escapes the segments of a page name, or of a file, for a link.
+func pageURL(name string) string {
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// defaultTitle is the title of a page without one in its front matter.
+func defaultTitle(name string) string {
+	return strings.Replace(name, "-", " ", -1)
+}
+
+// frontMatter splits the front matter off the content of a page. It returns
+// the fields of the front matter, if there is one, and the rest of the
+// content.
+func frontMatter(content []byte) (map[string]string, []byte) {
+	fields := make(map[string]string)
+	if !bytes.HasPrefix(content, []byte("---\n")) && !bytes.HasPrefix(content, []byte("---\r\n")) {
+		return fields, content
+	}
+	rest := bytes.Replace(content, []byte("\r\n"), []byte("\n"), -1)[4:]
+	// The front matter may end the content.
+	open := !bytes.HasSuffix(rest, []byte("\n"))
+	if open {
+		rest = append(rest, '\n')
+	}
+	end := 0
+	if !bytes.HasPrefix(rest, []byte("---\n")) {
+		if end = bytes.Index(rest, []byte("\n---\n")) + 1; end == 0 {
+			return fields, content
+		}
+	}
+	for _, line := range strings.Split(string(rest[:end]), "\n") {
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			return make(map[string]string), content
+		}
+		fields[strings.ToLower(strings.TrimSpace(parts[0]))] = strings.TrimSpace(parts[1])
+	}
+	if body := rest[end+4:]; !open || len(body) == 0 {
+		return fields, body
+	}
+	return fields, rest[end+4 : len(rest)-1]
+}
+
+// pageTitle returns the title of page, given its content.
+func pageTitle(name string, content []byte) string {
+	fields, _ := frontMatter(content)
+	if t, ok := fields["title"]; ok && len(t) > 0 {
+		return t
+	}
+	return defaultTitle(name)
+}
+
+// withTitle prepends the front matter that keeps title to body, if title
+// cannot be derived from the name of the page.
+func withTitle(name string, title string, body string) string {
+	if len(title) == 0 || defaultTitle(name) == title {
+		return body
+	}
+	return "---\ntitle: " + title + "\n---\n\n" + body
+}
+
+// titleOf returns the title of the wiki page shown on p, for the templates.
+// Other pages, such as search results, have none.
+func titleOf(p interface{}) string {
+	switch p := p.(type) {
+	case *page:
+		return p.Name
+	case *historyPage:
+		return p.Name
+	}
+	return ""
+}
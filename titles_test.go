@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"bicycle":                         "bicycle",
+		"Café Menu":                       "Café-Menu",
+		"Café Menu":                      "Café-Menu",
+		"Q&A":                             "Q-A",
+		"  What's new?  ":                 "What-s-new",
+		"transportation vehicles/bicycle": "transportation-vehicles/bicycle",
+		"life / goals //balance":          "life/goals/balance",
+		"日本語 ページ":                         "日本語-ページ",
+	}
+	for title, expected := range tests {
+		if name := slug(title); name != expected {
+			t.Errorf("Slug of %q should equal >%s<, but is >%s<", title, expected, name)
+		}
+	}
+	if url := pageURL("life/Café Menu"); url != "life/Caf%C3%A9%20Menu" {
+		t.Errorf("URL should equal >life/Caf%%C3%%A9%%20Menu<, but is >%s<", url)
+	}
+}
+
+func TestFrontMatter(t *testing.T) {
+	fields, body := frontMatter([]byte("---\ntitle: Q&A\nredirect: FAQ\n---\n\nQuestions"))
+	if fields["title"] != "Q&A" || fields["redirect"] != "FAQ" || string(body) != "\nQuestions" {
+		t.Errorf("Front matter should be split off, but got >%v< and >%s<", fields, body)
+	}
+	fields, body = frontMatter([]byte("---\nJust a rule\n---\n"))
+	if len(fields) != 0 || string(body) != "---\nJust a rule\n---\n" {
+		t.Errorf("Content without front matter should be left alone, but got >%v< and >%s<", fields, body)
+	}
+
+	if name := pageTitle("Q-A", []byte(withTitle("Q-A", "Q&A", "Questions"))); name != "Q&A" {
+		t.Errorf("Title should equal >Q&A<, but is >%s<", name)
+	}
+	if body := withTitle("Café-Menu", "Café Menu", "Menu"); body != "Menu" {
+		t.Errorf("Title that follows from the name should not be kept, but got >%s<", body)
+	}
+}
@@ -0,0 +1,453 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	auth "github.com/abbot/go-http-auth"
+	"github.com/boombuler/barcode/qr"
+)
+
+const (
+	totpPeriod        = 30
+	totpDigits        = 6
+	recoveryCodeCount = 10
+	twoFactorCookie   = "goiki_2fa"
+)
+
+var twoFactors *totpStore
+
+type twoFactorPage struct {
+	SiteName      string
+	Title         string
+	Theme         string
+	Mode          string
+	Next          string
+	Secret        string
+	QRCode        string
+	RecoveryCodes []string
+	Required      bool
+	Error         string
+}
+
+// totpUser is the two-factor enrollment of a single user. Recovery codes are
+// stored as SHA-256 hashes and removed once used.
+type totpUser struct {
+	Secret   string
+	Recovery []string
+	LastStep int64
+}
+
+// totpStore keeps the two-factor enrollments in a JSON file outside of the
+// Git repo, along with enrollments that still await confirmation and the key
+// used to sign two-factor session cookies.
+type totpStore struct {
+	sync.Mutex
+	file    string
+	users   map[string]*totpUser
+	pending map[string]string
+	key     []byte
+	now     func() time.Time
+}
+
+func loadTotpStore(file string) (*totpStore, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	s := &totpStore{
+		file:    file,
+		users:   make(map[string]*totpUser),
+		pending: make(map[string]string),
+		key:     key,
+		now:     time.Now,
+	}
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return s, json.Unmarshal(data, &s.users)
+}
+
+// saveJSON writes v as JSON to the file at path in the state dir, creating
+// the dir if needed. The file is replaced at once, so that it is never left
+// half written.
+func saveJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *totpStore) save() error {
+	return saveJSON(s.file, s.users)
+}
+
+func (s *totpStore) enrolled(username string) bool {
+	s.Lock()
+	defer s.Unlock()
+	_, ok := s.users[username]
+	return ok
+}
+
+// begin starts an enrollment for the user and returns its secret. The
+// enrollment is only stored once confirmed with a code by finish.
+func (s *totpStore) begin(username string) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+	if secret, ok := s.pending[username]; ok {
+		return secret, nil
+	}
+	secret, err := newTotpSecret()
+	if err != nil {
+		return "", err
+	}
+	s.pending[username] = secret
+	return secret, nil
+}
+
+// finish confirms a pending enrollment and returns the user's recovery codes.
+func (s *totpStore) finish(username, code string) ([]string, error) {
+	s.Lock()
+	defer s.Unlock()
+	secret, ok := s.pending[username]
+	if !ok {
+		return nil, fmt.Errorf("No pending enrollment for %s", username)
+	}
+	step, ok := totpMatch(secret, code, s.now(), 0)
+	if !ok {
+		return nil, fmt.Errorf("Invalid authentication code")
+	}
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		c, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = c
+		hashes[i] = hashRecoveryCode(c)
+	}
+	s.users[username] = &totpUser{Secret: secret, Recovery: hashes, LastStep: step}
+	delete(s.pending, username)
+	return codes, s.save()
+}
+
+// verify checks a TOTP code or an unused recovery code for the user. Codes
+// cannot be replayed: a TOTP code is only accepted for a time step after the
+// last accepted one and a recovery code is removed once used.
+func (s *totpStore) verify(username, code string) bool {
+	s.Lock()
+	defer s.Unlock()
+	u, ok := s.users[username]
+	if !ok {
+		return false
+	}
+	if step, ok := totpMatch(u.Secret, code, s.now(), u.LastStep); ok {
+		u.LastStep = step
+		if err := s.save(); err != nil {
+			log.Printf("Unable to save two-factor enrollments: %v\n", err)
+		}
+		return true
+	}
+	hash := hashRecoveryCode(code)
+	for i, h := range u.Recovery {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+			u.Recovery = append(u.Recovery[:i], u.Recovery[i+1:]...)
+			if err := s.save(); err != nil {
+				log.Printf("Unable to save two-factor enrollments: %v\n", err)
+			}
+			log.Printf("Recovery code used by %s, %d left\n", username, len(u.Recovery))
+			return true
+		}
+	}
+	return false
+}
+
+func (s *totpStore) remove(username string) error {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.users, username)
+	return s.save()
+}
+
+// cookie returns a session cookie proving the user passed the second factor.
+func (s *totpStore) cookie(username string) *http.Cookie {
+	expires := s.now().Add(conf.TwoFactor.Session.Duration)
+	value := username + "|" + strconv.FormatInt(expires.Unix(), 10)
+	return &http.Cookie{
+		Name:     twoFactorCookie,
+		Value:    url.QueryEscape(value + "|" + s.sign(value)),
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+	}
+}
+
+func (s *totpStore) verified(r *http.Request, username string) bool {
+	c, err := r.Cookie(twoFactorCookie)
+	if err != nil {
+		return false
+	}
+	value, err := url.QueryUnescape(c.Value)
+	if err != nil {
+		return false
+	}
+	parts := strings.Split(value, "|")
+	if len(parts) != 3 || parts[0] != username {
+		return false
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(s.sign(parts[0]+"|"+parts[1]))) {
+		return false
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	return err == nil && s.now().Unix() < expires
+}
+
+func (s *totpStore) sign(value string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newTotpSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret), nil
+}
+
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+	return code[:4] + "-" + code[4:], nil
+}
+
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.Replace(strings.TrimSpace(code), "-", "", -1))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// totpCode computes the RFC 6238 code of a base32 secret for a time step.
+func totpCode(secret string, step int64) (string, error) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0xf
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000), nil
+}
+
+// totpMatch checks a code against the time steps around now, allowing for one
+// step of clock drift, and returns the matching step. Steps up to and
+// including after are not accepted.
+func totpMatch(secret, code string, now time.Time, after int64) (int64, bool) {
+	code = strings.Replace(strings.TrimSpace(code), " ", "", -1)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	current := now.Unix() / totpPeriod
+	for step := current - 1; step <= current+1; step++ {
+		if step <= after {
+			continue
+		}
+		expected, err := totpCode(secret, step)
+		if err == nil && subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+func totpURI(issuer, username, secret string) string {
+	label := url.PathEscape(issuer + ":" + username)
+	return fmt.Sprintf("otpauth://totp/%s?secret=%s&issuer=%s&period=%d&digits=%d",
+		label, secret, url.QueryEscape(issuer), totpPeriod, totpDigits)
+}
+
+// qrCodeSVG renders content as a QR code in an SVG image.
+func qrCodeSVG(content string) (string, error) {
+	code, err := qr.Encode(content, qr.M, qr.Auto)
+	if err != nil {
+		return "", err
+	}
+	const quiet = 4
+	bounds := code.Bounds()
+	size := bounds.Dx() + 2*quiet
+	var path bytes.Buffer
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if r, _, _, _ := code.At(x, y).RGBA(); r == 0 {
+				fmt.Fprintf(&path, "M%d %dh1v1h-1z", x-bounds.Min.X+quiet, y-bounds.Min.Y+quiet)
+			}
+		}
+	}
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d" shape-rendering="crispEdges">`+
+		`<rect width="%d" height="%d" fill="#fff"/><path d="%s" fill="#000"/></svg>`,
+		size, size, size*6, size*6, size, size, path.String()), nil
+}
+
+// twoFactorWrap requires users who enrolled for two-factor authentication to
+// pass their second factor before reaching the handler. When two-factor
+// authentication is required, users who did not enroll yet are sent to the
+// enrollment first.
+func twoFactorWrap(fn auth.AuthenticatedHandlerFunc) auth.AuthenticatedHandlerFunc {
+	return func(w http.ResponseWriter, r *auth.AuthenticatedRequest) {
+		if twoFactors == nil || twoFactors.verified(&r.Request, r.Username) {
+			fn(w, r)
+			return
+		}
+		target := "/2fa/verify"
+		if !twoFactors.enrolled(r.Username) {
+			if !conf.TwoFactor.Required {
+				fn(w, r)
+				return
+			}
+			target = "/2fa/enroll"
+		}
+		http.Redirect(w, &r.Request, target+"?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+	}
+}
+
+func twoFactorHandler(w http.ResponseWriter, r *auth.AuthenticatedRequest) {
+	if twoFactors == nil {
+		http.NotFound(w, &r.Request)
+		return
+	}
+	next := r.FormValue("next")
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") {
+		next = "/"
+	}
+	p := &twoFactorPage{Title: "Two-factor authentication", Theme: conf.Theme, SiteName: conf.Name, Next: next,
+		Required: conf.TwoFactor.Required}
+
+	switch r.URL.Path {
+	case "/2fa/enroll":
+		twoFactorEnroll(w, r, p)
+	case "/2fa/verify":
+		twoFactorVerify(w, r, p)
+	case "/2fa/disable":
+		if r.Method != "POST" || !twoFactors.verified(&r.Request, r.Username) || conf.TwoFactor.Required {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if err := twoFactors.remove(r.Username); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		log.Printf("Two-factor authentication disabled for %s\n", r.Username)
+		http.Redirect(w, &r.Request, "/2fa/enroll", http.StatusFound)
+	default:
+		http.Redirect(w, &r.Request, "/2fa/enroll", http.StatusFound)
+	}
+}
+
+func twoFactorEnroll(w http.ResponseWriter, r *auth.AuthenticatedRequest, p *twoFactorPage) {
+	if twoFactors.enrolled(r.Username) {
+		if !twoFactors.verified(&r.Request, r.Username) {
+			http.Redirect(w, &r.Request, "/2fa/verify?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+			return
+		}
+		p.Mode = "enrolled"
+		renderTemplate(w, "twofactor", p)
+		return
+	}
+
+	if r.Method == "POST" {
+		codes, err := twoFactors.finish(r.Username, r.FormValue("code"))
+		if err == nil {
+			log.Printf("Two-factor authentication enabled for %s\n", r.Username)
+			http.SetCookie(w, twoFactors.cookie(r.Username))
+			p.Mode = "recovery"
+			p.RecoveryCodes = codes
+			renderTemplate(w, "twofactor", p)
+			return
+		}
+		p.Error = err.Error()
+	}
+
+	secret, err := twoFactors.begin(r.Username)
+	if err == nil {
+		p.Secret = secret
+		p.QRCode, err = qrCodeSVG(totpURI(conf.TwoFactor.Issuer, r.Username, secret))
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	p.Mode = "enroll"
+	renderTemplate(w, "twofactor", p)
+}
+
+func twoFactorVerify(w http.ResponseWriter, r *auth.AuthenticatedRequest, p *twoFactorPage) {
+	p.Mode = "verify"
+	if r.Method != "POST" {
+		renderTemplate(w, "twofactor", p)
+		return
+	}
+
+	key := "2fa:" + r.Username
+	if logins != nil {
+		if wait := logins.wait(key); wait > 0 {
+			log.Printf("Blocked two-factor attempt for %q from %s, throttled for another %v\n", r.Username, r.RemoteAddr, wait)
+			p.Error = "Too many failed attempts, please try again later."
+			w.WriteHeader(http.StatusTooManyRequests)
+			renderTemplate(w, "twofactor", p)
+			return
+		}
+	}
+	if !twoFactors.verify(r.Username, r.FormValue("code")) {
+		log.Printf("Failed two-factor attempt for %q from %s\n", r.Username, r.RemoteAddr)
+		if logins != nil {
+			logins.fail(key)
+		}
+		p.Error = "Invalid authentication code"
+		renderTemplate(w, "twofactor", p)
+		return
+	}
+	if logins != nil {
+		logins.succeed(key)
+	}
+	http.SetCookie(w, twoFactors.cookie(r.Username))
+	http.Redirect(w, &r.Request, p.Next, http.StatusFound)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The secret "12345678901234567890" from the RFC 6238 test vectors.
+const testTotpSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTotpCode(t *testing.T) {
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for timestamp, expected := range vectors {
+		code, err := totpCode(testTotpSecret, timestamp/totpPeriod)
+		if err != nil || code != expected {
+			t.Errorf("Code at %d should equal >%s<, but is >%s< (error: %v)", timestamp, expected, code, err)
+		}
+	}
+}
+
+func TestTotpStore(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "goiki-2fa")
+	defer os.RemoveAll(dir)
+
+	now := time.Unix(1111111109, 0)
+	s, err := loadTotpStore(filepath.Join(dir, "totp.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.now = func() time.Time { return now }
+	s.pending["alice"] = testTotpSecret
+
+	if _, err = s.finish("alice", "000000"); err == nil {
+		t.Errorf("Enrollment with a wrong code should fail")
+	}
+	codes, err := s.finish("alice", "081804")
+	if err != nil || len(codes) != recoveryCodeCount {
+		t.Fatalf("Enrollment should return %d recovery codes, but returned %d (error: %v)", recoveryCodeCount, len(codes), err)
+	}
+
+	if s.verify("alice", "081804") {
+		t.Errorf("A code should not be accepted twice")
+	}
+	now = now.Add(totpPeriod * time.Second)
+	code, _ := totpCode(testTotpSecret, now.Unix()/totpPeriod)
+	if !s.verify("alice", code) {
+		t.Errorf("The code of the next time step should be accepted")
+	}
+
+	if !s.verify("alice", strings.ToUpper(codes[0])) {
+		t.Errorf("A recovery code should be accepted")
+	}
+	if s.verify("alice", codes[0]) {
+		t.Errorf("A recovery code should not be accepted twice")
+	}
+
+	reloaded, err := loadTotpStore(s.file)
+	if err != nil || !reloaded.enrolled("alice") {
+		t.Errorf("Enrollment should be persisted (error: %v)", err)
+	}
+	if len(reloaded.users["alice"].Recovery) != recoveryCodeCount-1 {
+		t.Errorf("Number of recovery codes left should equal %d, but is %d", recoveryCodeCount-1, len(reloaded.users["alice"].Recovery))
+	}
+}
+
+func TestTwoFactorCookie(t *testing.T) {
+	conf.TwoFactor.Session.Duration = time.Hour
+	defer func() { conf = config{} }()
+
+	s, _ := loadTotpStore(filepath.Join(os.TempDir(), "goiki-2fa-missing", "totp.json"))
+	r, _ := http.NewRequest("GET", "/edit/home", nil)
+	r.AddCookie(s.cookie("alice"))
+
+	if !s.verified(r, "alice") {
+		t.Errorf("Cookie should verify for the user it was issued to")
+	}
+	if s.verified(r, "bob") {
+		t.Errorf("Cookie should not verify for another user")
+	}
+
+	s.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if s.verified(r, "alice") {
+		t.Errorf("Cookie should not verify once expired")
+	}
+}
+
+func TestQRCodeSVG(t *testing.T) {
+	svg, err := qrCodeSVG(totpURI("Goiki", "alice", testTotpSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(svg, "<svg ") || !strings.Contains(svg, "<path d=\"M") {
+		t.Errorf("QR code should be rendered as an SVG path, but was >%s<", svg)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+var heads headWatch
+
+// headWatch tracks the HEAD commit of the repo and notifies the registered
+// invalidators whenever it changes, whether by a save, a sync or a commit
+// made outside of Goiki.
+type headWatch struct {
+	sync.Mutex
+	head         string
+	invalidators []func()
+}
+
+// onHeadChange registers fn to be called when HEAD changes. Anything derived
+// from the repo content, such as caches and indexes, should register here.
+func onHeadChange(fn func()) {
+	heads.Lock()
+	defer heads.Unlock()
+	heads.invalidators = append(heads.invalidators, fn)
+}
+
+// checkHead compares HEAD with the last known HEAD and runs the invalidators
+// if it moved. It returns whether HEAD changed.
+func checkHead() bool {
+	head, err := store.Head()
+	if err != nil {
+		log.Printf("Unable to find HEAD: %v\n", err)
+		return false
+	}
+	heads.Lock()
+	defer heads.Unlock()
+	if head == heads.head {
+		return false
+	}
+	log.Printf("HEAD moved from %.7s to %.7s\n", heads.head, head)
+	heads.head = head
+	for _, fn := range heads.invalidators {
+		fn()
+	}
+	return true
+}
+
+// watchRepo watches the refs of the repo for changes made outside of Goiki,
+// such as pushes to or commits in the data directory, and checks HEAD once
+// the changes settle. Without a Git repo, the data directory itself is
+// watched.
+func watchRepo(dir string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	gitDir := filepath.Join(dir, ".git")
+	paths := []string{gitDir}
+	root := filepath.Join(gitDir, "refs", "heads")
+	if _, err = os.Stat(gitDir); os.IsNotExist(err) {
+		paths, root = nil, dir
+	}
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.IsDir() {
+			if path != root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	for _, path := range paths {
+		if err = watcher.Add(path); err != nil {
+			watcher.Close()
+			return err
+		}
+	}
+
+	go func() {
+		var settle <-chan time.Time
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if strings.HasSuffix(event.Name, ".lock") {
+					continue
+				}
+				if event.Op&fsnotify.Create != 0 {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						watcher.Add(event.Name)
+					}
+				}
+				settle = time.After(250 * time.Millisecond)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("Error watching %s: %v\n", dir, err)
+			case <-settle:
+				settle = nil
+				checkHead()
+			}
+		}
+	}()
+	return nil
+}
+
+// hookHandler lets a Git hook, such as post-receive, tell Goiki that the repo
+// changed.
+func hookHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if checkHead() {
+		w.Write([]byte("changed\n"))
+	} else {
+		w.Write([]byte("unchanged\n"))
+	}
+}